@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/LouisRemes-95/gator/internal/config"
+)
+
+// handlerAgg collects feeds every interval until SIGINT or SIGTERM, letting
+// the round in progress finish first. SIGHUP reloads the config and SIGUSR1
+// starts a round right away. A pid file keeps a second agg from starting, and
+// with an HTTP address metrics and health checks are served for the whole run.
+func handlerAgg(s *state, cmd command) error {
+	flags := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	pidFile := flags.String("pid-file", "", "pid file locking out a second agg, defaults to pid_file or ~/.gator-agg.pid")
+	httpAddr := flags.String("http-addr", s.cfg.HTTPAddr, "address serving /metrics, /healthz and /readyz, such as :9090, off when empty")
+	err := flags.Parse(cmd.Args)
+	if err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+	args := flags.Args()
+
+	if len(args) == 0 {
+		return errors.New("usage: agg [--pid-file path] [--http-addr addr] <interval>")
+	}
+
+	timeBetweenReps, err := time.ParseDuration(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse duration: %w", err)
+	}
+
+	if *pidFile == "" {
+		*pidFile, err = s.cfg.PIDFile()
+		if err != nil {
+			return err
+		}
+	}
+	lock, err := lockPIDFile(*pidFile)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	health := newAggHealth(timeBetweenReps, s.cfg.SchedulerLagLimit())
+	if *httpAddr != "" {
+		server, err := startAggServer(s, health, *httpAddr)
+		if err != nil {
+			return err
+		}
+		defer server.stop()
+	}
+
+	signals := notifySignals()
+	defer signals.stop()
+
+	slog.Info("agg started", "pid", os.Getpid(), "interval", timeBetweenReps, "pid_file", *pidFile)
+
+	ticker := time.NewTicker(timeBetweenReps)
+	defer ticker.Stop()
+	var lastPruned time.Time
+	for {
+		// Reloads happen on this goroutine, between rounds.
+		health.setLagLimit(s.cfg.SchedulerLagLimit())
+
+		fetched, err := scrapeFeeds(s)
+		if err != nil {
+			slog.Error("failed to scrape feeds", "error", err)
+		}
+		// A round counts as progress unless every feed in it failed.
+		health.roundDone(err == nil || fetched > 0)
+
+		if s.cfg.PruneOnAgg && time.Since(lastPruned) >= pruneInterval {
+			lastPruned = time.Now()
+			links, posts, err := prunePosts(s)
+			if err != nil {
+				slog.Error("failed to prune posts", "error", err)
+			} else {
+				slog.Info("pruned posts", "unlinked", links, "deleted", posts)
+			}
+		}
+
+		if !waitForRound(s, ticker, signals) {
+			slog.Info("agg stopped", "pid", os.Getpid())
+			return nil
+		}
+	}
+}
+
+// waitForRound blocks until the next round is due and reports whether agg
+// should go on. A config reload keeps waiting for the tick.
+func waitForRound(s *state, ticker *time.Ticker, signals aggSignals) bool {
+	for {
+		// A stop received during the round wins over a tick already due.
+		select {
+		case sig := <-signals.shutdown:
+			slog.Info("draining and shutting down", "signal", sig.String())
+			return false
+		default:
+		}
+
+		select {
+		case sig := <-signals.shutdown:
+			slog.Info("draining and shutting down", "signal", sig.String())
+			return false
+		case <-signals.reload:
+			reloadConfig(s)
+		case <-signals.refresh:
+			slog.Info("refresh requested")
+			return true
+		case <-ticker.C:
+			return true
+		}
+	}
+}
+
+// reloadConfig rereads the config file, keeping the old one when it is
+// invalid. The database connection is not reopened.
+func reloadConfig(s *state) {
+	cfg, err := config.Read()
+	if err != nil {
+		slog.Error("failed to reload the config, keeping the current one", "error", err)
+		return
+	}
+	if cfg.DbUrl != s.cfg.DbUrl {
+		slog.Warn("db_url changed, restart agg to use it")
+	}
+
+	*s.cfg = cfg
+	s.fetcher = newFeedFetcher(s.cfg)
+	slog.Info("config reloaded")
+}
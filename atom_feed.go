@@ -0,0 +1,112 @@
+package main
+
+import (
+	"cmp"
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+type AtomFeed struct {
+	XMLName   xml.Name    `xml:"feed"`
+	Title     AtomText    `xml:"title"`
+	Subtitle  AtomText    `xml:"subtitle"`
+	Links     []AtomLink  `xml:"link"`
+	Generator string      `xml:"generator"`
+	Icon      string      `xml:"icon"`
+	Logo      string      `xml:"logo"`
+	Entries   []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	ID         string         `xml:"id"`
+	Title      AtomText       `xml:"title"`
+	Links      []AtomLink     `xml:"link"`
+	Summary    AtomText       `xml:"summary"`
+	Content    AtomText       `xml:"content"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Authors    []AtomPerson   `xml:"author"`
+	Categories []AtomCategory `xml:"category"`
+}
+
+type AtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// AtomText is an Atom text construct. Text and html content arrive as
+// character data, xhtml content as markup inside a div.
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// RSS converts the feed to the RSS structure the rest of gator works with.
+func (f *AtomFeed) RSS() *RSSFeed {
+	feed := &RSSFeed{}
+	feed.Channel.Title = f.Title.String()
+	feed.Channel.Link = alternateLink(f.Links)
+	feed.Channel.Description = f.Subtitle.String()
+	feed.Channel.Generator = strings.TrimSpace(f.Generator)
+	feed.Channel.Image.URL = strings.TrimSpace(cmp.Or(f.Logo, f.Icon))
+
+	for _, entry := range f.Entries {
+		item := RSSItem{
+			Title:       entry.Title.String(),
+			Link:        alternateLink(entry.Links),
+			Description: entry.Summary.String(),
+			PubDate:     rssDate(cmp.Or(entry.Published, entry.Updated)),
+			GUID:        entry.ID,
+			Content:     entry.Content.String(),
+		}
+		if len(entry.Authors) > 0 {
+			item.Author = entry.Authors[0].Name
+		}
+		for _, category := range entry.Categories {
+			if category.Term != "" {
+				item.Categories = append(item.Categories, category.Term)
+			}
+		}
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
+	return feed
+}
+
+// alternateLink returns the link to the page itself, which Atom marks with
+// rel="alternate" or no rel at all.
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
+
+// rssDate reformats an RFC 3339 date as an RSS pubDate. Dates it cannot parse
+// are returned as is, so the item is skipped with the date in the warning.
+func rssDate(value string) string {
+	value = strings.TrimSpace(value)
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Format(time.RFC1123Z)
+}
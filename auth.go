@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/LouisRemes-95/gator/internal/config"
+	"github.com/LouisRemes-95/gator/internal/database"
+	"github.com/LouisRemes-95/gator/internal/secret"
+	"github.com/google/uuid"
+)
+
+const (
+	authBasic  = "basic"
+	authBearer = "bearer"
+)
+
+// feedAuth holds the credentials sent with every request for a feed. Only the
+// type is stored in clear, the rest is encrypted with the credentials key.
+type feedAuth struct {
+	Type     string `json:"-"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+func (a *feedAuth) apply(request *http.Request) {
+	switch a.Type {
+	case authBasic:
+		request.SetBasicAuth(a.Username, a.Password)
+	case authBearer:
+		request.Header.Set("Authorization", "Bearer "+a.Token)
+	}
+}
+
+// basicAuthFlag parses "user:password" into basic credentials.
+type basicAuthFlag struct {
+	auth **feedAuth
+}
+
+func (f basicAuthFlag) String() string {
+	return ""
+}
+
+func (f basicAuthFlag) Set(value string) error {
+	username, password, ok := strings.Cut(value, ":")
+	if !ok || username == "" {
+		return errors.New(`basic credentials must be "user:password"`)
+	}
+	*f.auth = &feedAuth{
+		Type:     authBasic,
+		Username: username,
+		Password: password,
+	}
+	return nil
+}
+
+// bearerAuthFlag parses a token into bearer credentials.
+type bearerAuthFlag struct {
+	auth **feedAuth
+}
+
+func (f bearerAuthFlag) String() string {
+	return ""
+}
+
+func (f bearerAuthFlag) Set(value string) error {
+	if value == "" {
+		return errors.New("bearer token is empty")
+	}
+	*f.auth = &feedAuth{
+		Type:  authBearer,
+		Token: value,
+	}
+	return nil
+}
+
+// parseAuthArgs reads "basic <user> [password]", "bearer [token]" or "none".
+// A missing password or token is read from standard input so that it stays
+// out of the shell history.
+func parseAuthArgs(args []string) (*feedAuth, error) {
+	if len(args) == 0 {
+		return nil, errors.New("missing auth type, one of basic, bearer or none")
+	}
+
+	switch args[0] {
+	case "none":
+		return nil, nil
+	case authBasic:
+		if len(args) < 2 {
+			return nil, errors.New("usage: basic <user> [password]")
+		}
+		auth := &feedAuth{
+			Type:     authBasic,
+			Username: args[1],
+		}
+		if len(args) > 2 {
+			auth.Password = args[2]
+			return auth, nil
+		}
+		password, err := readSecret("Password: ")
+		if err != nil {
+			return nil, err
+		}
+		auth.Password = password
+		return auth, nil
+	case authBearer:
+		token := ""
+		if len(args) > 1 {
+			token = args[1]
+		} else {
+			var err error
+			token, err = readSecret("Token: ")
+			if err != nil {
+				return nil, err
+			}
+		}
+		if token == "" {
+			return nil, errors.New("bearer token is empty")
+		}
+		return &feedAuth{
+			Type:  authBearer,
+			Token: token,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown auth type %q, one of basic, bearer or none", args[0])
+	}
+}
+
+// sealFeedAuth encrypts the credentials of a feed, ready to be stored. It
+// fails without a credentials key, so callers seal before writing anything.
+func sealFeedAuth(cfg *config.Config, feedID uuid.UUID, auth *feedAuth) (database.SetFeedAuthParams, error) {
+	params := database.SetFeedAuthParams{
+		ID: feedID,
+	}
+	if auth == nil {
+		return params, nil
+	}
+
+	key, err := cfg.CredentialsKey()
+	if err != nil {
+		return database.SetFeedAuthParams{}, err
+	}
+
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return database.SetFeedAuthParams{}, fmt.Errorf("failed to encode the credentials: %w", err)
+	}
+
+	sealed, err := secret.Seal(key, data)
+	if err != nil {
+		return database.SetFeedAuthParams{}, fmt.Errorf("failed to encrypt the credentials: %w", err)
+	}
+
+	params.AuthType = nullString(auth.Type)
+	params.AuthSecret = sealed
+	return params, nil
+}
+
+func readSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// setFeedAuth encrypts and stores the credentials of a feed, nil removes them.
+func setFeedAuth(s *state, feedID uuid.UUID, auth *feedAuth) error {
+	params, err := sealFeedAuth(s.cfg, feedID, auth)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.SetFeedAuth(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("failed to store the feed credentials: %w", err)
+	}
+	return nil
+}
+
+// openFeedAuth decrypts the credentials stored with a feed, if any.
+func openFeedAuth(cfg *config.Config, authType sql.NullString, sealed []byte) (*feedAuth, error) {
+	if !authType.Valid {
+		return nil, nil
+	}
+
+	key, err := cfg.CredentialsKey()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := secret.Open(key, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the feed credentials: %w", err)
+	}
+
+	auth := &feedAuth{
+		Type: authType.String,
+	}
+	err = json.Unmarshal(data, auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the feed credentials: %w", err)
+	}
+	return auth, nil
+}
+
+// authLabel describes the credentials of a feed without revealing them.
+func authLabel(authType sql.NullString) string {
+	if !authType.Valid {
+		return "none"
+	}
+	return authType.String
+}
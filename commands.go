@@ -3,21 +3,30 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
-	"os"
+	"log/slog"
+	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/LouisRemes-95/gator/internal/canonical"
 	"github.com/LouisRemes-95/gator/internal/config"
 	"github.com/LouisRemes-95/gator/internal/database"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db      *database.Queries
+	sqlDB   *sql.DB
+	cfg     *config.Config
+	fetcher *feedFetcher
 }
 
 type command struct {
@@ -63,10 +72,29 @@ func registeredCommands() *commands {
 	programCommands.register("following", middlewareLoggedIn(handlerFollowing))
 	programCommands.register("unfollow", middlewareLoggedIn(handlerUnfollow))
 	programCommands.register("browse", middlewareLoggedIn(handlerBrowse))
+	programCommands.register("feed", handlerFeed)
+	programCommands.register("doctor", handlerDoctor)
+	programCommands.register("gc", handlerGC)
+	programCommands.register("prune", handlerPrune)
+	programCommands.register("refresh", handlerRefresh)
 
 	return programCommands
 }
 
+func feedCommands() *commands {
+	feedCommands := &commands{
+		registeredCommands: make(map[string]func(*state, command) error),
+	}
+
+	feedCommands.register("info", handlerFeedInfo)
+	feedCommands.register("header", handlerFeedHeader)
+	feedCommands.register("auth", handlerFeedAuth)
+	feedCommands.register("history", handlerFeedHistory)
+	feedCommands.register("retention", handlerFeedRetention)
+
+	return feedCommands
+}
+
 // Command handlers
 
 func handlerLogin(s *state, cmd command) error {
@@ -77,11 +105,9 @@ func handlerLogin(s *state, cmd command) error {
 	_, err := s.db.GetUser(context.Background(), cmd.Args[0])
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			fmt.Println("Error: User does not exist in the database.")
-			os.Exit(1)
-		} else {
-			return fmt.Errorf("failed to get user from db: %w", err)
+			return fmt.Errorf("user %s does not exist", cmd.Args[0])
 		}
+		return fmt.Errorf("failed to get user from db: %w", err)
 	}
 
 	err = s.cfg.SetUser(cmd.Args[0])
@@ -109,8 +135,7 @@ func handlerRegister(s *state, cmd command) error {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) {
 			if pqErr.Code == "23505" {
-				fmt.Println("Error: User with that name already exists!")
-				os.Exit(1)
+				return fmt.Errorf("user %s already exists", cmd.Args[0])
 			}
 		}
 		return fmt.Errorf("failed to create user in db: %w", err)
@@ -152,63 +177,119 @@ func handlerUsers(s *state, _ command) error {
 	return nil
 }
 
-func handlerAgg(s *state, cmd command) error {
-	if len(cmd.Args) == 0 {
+func handlerAddFeed(s *state, cmd command, user database.User) error {
+	headers := headerFlag{}
+	var auth *feedAuth
+	flags := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	flags.Var(headers, "header", "extra request header as \"Name: value\", repeatable")
+	flags.Var(basicAuthFlag{&auth}, "basic", "HTTP Basic credentials as \"user:password\"")
+	flags.Var(bearerAuthFlag{&auth}, "bearer", "bearer token")
+	err := flags.Parse(cmd.Args)
+	if err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+	args := flags.Args()
+
+	if len(args) == 0 {
 		return errors.New("command arg's slice empty")
 	}
 
-	timeBetweenReps, err := time.ParseDuration(cmd.Args[0])
+	// addfeed [name] <url>: the name defaults to the channel title.
+	var name, pageURL string
+	if len(args) == 1 {
+		pageURL = args[0]
+	} else {
+		name, pageURL = args[0], args[1]
+	}
+	opts := fetchOptions{
+		Headers: headers,
+		Auth:    auth,
+	}
+
+	user, err = s.db.GetUser(context.Background(), s.cfg.CurrentUserName)
 	if err != nil {
-		return fmt.Errorf("failed to parse duration: %w", err)
+		return fmt.Errorf("failed to get the current user: %w", err)
 	}
 
-	fmt.Println("Collecting feeds every %w", timeBetweenReps)
-	ticker := time.NewTicker(timeBetweenReps)
-	for ; ; <-ticker.C {
-		err := scrapeFeeds(s)
-		if err != nil {
-			fmt.Println("failed to scrape feeds", err)
-		}
+	candidates, err := s.fetcher.discoverFeeds(context.Background(), pageURL, opts)
+	if err != nil {
+		return fmt.Errorf("failed to discover feeds: %w", err)
+	}
+	feedURL, err := pickFeed(pageURL, candidates)
+	if err != nil {
+		return err
+	}
+	// The page's links are untrusted, credentials and headers only go to the
+	// host they were given for.
+	if (len(headers) > 0 || auth != nil) && hostOf(feedURL) != hostOf(pageURL) {
+		return fmt.Errorf("the feed found at %s is on %s, not sending credentials or headers there: run addfeed on the feed url itself if you trust it", pageURL, hostOf(feedURL))
+	}
+	if feedURL != pageURL {
+		fmt.Println("Using feed found at: ", feedURL)
 	}
-}
 
-func handlerAddFeed(s *state, cmd command, user database.User) error {
-	if len(cmd.Args) < 2 {
-		return errors.New("command arg's slice has less than 2 elements")
+	result, err := s.fetcher.fetchFeed(context.Background(), feedURL, opts)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid feed: %w", feedURL, err)
+	}
+	rssFeed := result.Feed
+	if result.PermanentURL != "" {
+		feedURL = result.PermanentURL
+		fmt.Println("Feed moved permanently to: ", feedURL)
 	}
 
-	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUserName)
+	if name == "" {
+		name = rssFeed.Channel.Title
+	}
+	if name == "" {
+		name = feedURL
+	}
+
+	canonicalURL, err := canonical.URL(feedURL)
 	if err != nil {
-		return fmt.Errorf("failed to get the current user: %w", err)
+		return fmt.Errorf("failed to canonicalise url: %w", err)
 	}
 
 	myParams := database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      cmd.Args[0],
-		Url:       cmd.Args[1],
-		UserID:    user.ID,
+		ID:           uuid.New(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		Name:         name,
+		Url:          feedURL,
+		UserID:       user.ID,
+		Description:  nullString(rssFeed.Channel.Description),
+		SiteUrl:      nullString(rssFeed.Channel.Link),
+		CanonicalUrl: canonicalURL,
+	}
+
+	// Everything that can fail without the database is done first, and the
+	// feed is stored with its follow, headers and credentials or not at all.
+	headersParams, err := feedHeadersParams(myParams.ID, headers)
+	if err != nil {
+		return err
+	}
+	authParams, err := sealFeedAuth(s.cfg, myParams.ID, auth)
+	if err != nil {
+		return err
 	}
 
-	feed, err := s.db.CreateFeed(context.Background(), myParams)
+	tx, err := s.sqlDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	feed, err := qtx.CreateFeed(context.Background(), myParams)
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) {
 			if pqErr.Code == "23505" {
-				fmt.Println("Error: Feed with that url already exists!")
-				os.Exit(1)
+				return fmt.Errorf("feed %s already exists", feedURL)
 			}
 		}
 		return fmt.Errorf("failed to create user in db: %w", err)
 	}
-	fmt.Println("Feed created:")
-	fmt.Println("ID: ", feed.ID)
-	fmt.Println("Name: ", feed.Name)
-	fmt.Println("Create at: ", feed.CreatedAt)
-	fmt.Println("Updated at ", feed.UpdatedAt)
-	fmt.Println("Url: ", feed.Url)
-	fmt.Println("UserID: ", feed.UserID)
 
 	FeedFollowParams := database.CreateFeedFollowParams{
 		ID:     uuid.New(),
@@ -216,11 +297,44 @@ func handlerAddFeed(s *state, cmd command, user database.User) error {
 		FeedID: feed.ID,
 	}
 
-	_, err = s.db.CreateFeedFollow(context.Background(), FeedFollowParams)
+	_, err = qtx.CreateFeedFollow(context.Background(), FeedFollowParams)
 	if err != nil {
 		return fmt.Errorf("failed to create a feedfollow entry: %w", err)
 	}
 
+	err = qtx.UpdateFeedMetadata(context.Background(), feedMetadataParams(feed.ID, rssFeed))
+	if err != nil {
+		return fmt.Errorf("failed to store the feed metadata: %w", err)
+	}
+
+	if len(headers) > 0 {
+		err = qtx.SetFeedHeaders(context.Background(), headersParams)
+		if err != nil {
+			return fmt.Errorf("failed to store the feed headers: %w", err)
+		}
+	}
+
+	if auth != nil {
+		err = qtx.SetFeedAuth(context.Background(), authParams)
+		if err != nil {
+			return fmt.Errorf("failed to store the feed credentials: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	fmt.Println("Feed created:")
+	fmt.Println("ID: ", feed.ID)
+	fmt.Println("Name: ", feed.Name)
+	fmt.Println("Create at: ", feed.CreatedAt)
+	fmt.Println("Updated at ", feed.UpdatedAt)
+	fmt.Println("Url: ", feed.Url)
+	fmt.Println("Site: ", feed.SiteUrl.String)
+	fmt.Println("Description: ", feed.Description.String)
+	fmt.Println("UserID: ", feed.UserID)
 	return nil
 }
 
@@ -234,21 +348,128 @@ func handlerFeeds(s *state, _ command) error {
 		fmt.Println("Name: ", feed.FeedName)
 		fmt.Println("Url: ", feed.FeedUrl)
 		fmt.Println("User: ", feed.UserName)
+		fmt.Println("Title: ", feed.ChannelTitle.String)
+		fmt.Println("Site: ", feed.SiteUrl.String)
+		fmt.Println("Description: ", feed.Description.String)
+		fmt.Println("Language: ", feed.Language.String)
+		fmt.Println("Auth: ", authLabel(feed.AuthType))
+		fmt.Println("Status: ", feed.FetchStatus.String)
 		fmt.Println("")
 	}
 	return nil
 }
 
-func handlerFollow(s *state, cmd command, user database.User) error {
+func handlerFeed(s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return errors.New("command arg's slice empty")
+	}
+
+	return feedCommands().run(s, command{
+		Name: cmd.Args[0],
+		Args: cmd.Args[1:],
+	})
+}
+
+func handlerFeedHeader(s *state, cmd command) error {
+	if len(cmd.Args) < 2 {
+		return errors.New("usage: feed header <url> <name> [value], without a value the header is removed")
+	}
+
+	feed, err := getFeedByURL(s, cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get feed by url: %w", err)
+	}
+
+	headers, err := feedHeaders(feed)
+	if err != nil {
+		return err
+	}
+
+	name := http.CanonicalHeaderKey(cmd.Args[1])
+	if len(cmd.Args) > 2 {
+		headers[name] = strings.Join(cmd.Args[2:], " ")
+		fmt.Printf("Header %s set on %s\n", name, feed.Url)
+	} else {
+		delete(headers, name)
+		fmt.Printf("Header %s removed from %s\n", name, feed.Url)
+	}
+
+	return setFeedHeaders(s, feed.ID, headers)
+}
+
+func handlerFeedAuth(s *state, cmd command) error {
+	if len(cmd.Args) < 2 {
+		return errors.New("usage: feed auth <url> basic <user> [password] | bearer [token] | none")
+	}
+
+	feed, err := getFeedByURL(s, cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get feed by url: %w", err)
+	}
+
+	auth, err := parseAuthArgs(cmd.Args[1:])
+	if err != nil {
+		return err
+	}
+
+	err = setFeedAuth(s, feed.ID, auth)
+	if err != nil {
+		return err
+	}
+
+	if auth == nil {
+		fmt.Printf("Credentials removed from %s\n", feed.Url)
+	} else {
+		fmt.Printf("%s credentials set on %s\n", auth.Type, feed.Url)
+	}
+	return nil
+}
+
+func handlerFeedInfo(s *state, cmd command) error {
 	if len(cmd.Args) == 0 {
 		return errors.New("command arg's slice empty")
 	}
 
-	feed, err := s.db.GetFeedByUrl(context.Background(), cmd.Args[0])
+	feed, err := getFeedByURL(s, cmd.Args[0])
 	if err != nil {
 		return fmt.Errorf("failed to get feed by url: %w", err)
 	}
 
+	fmt.Println("Name: ", feed.Name)
+	fmt.Println("Url: ", feed.Url)
+	fmt.Println("Title: ", feed.ChannelTitle.String)
+	fmt.Println("Site: ", feed.SiteUrl.String)
+	fmt.Println("Description: ", feed.Description.String)
+	fmt.Println("Language: ", feed.Language.String)
+	fmt.Println("Image: ", feed.ImageUrl.String)
+	fmt.Println("Generator: ", feed.Generator.String)
+
+	headers, err := feedHeaders(feed)
+	if err != nil {
+		return err
+	}
+	// Header values may hold cookies or API keys, only show the names.
+	fmt.Println("Extra headers: ", headers.String())
+	fmt.Println("Auth: ", authLabel(feed.AuthType))
+	fmt.Println("Created at: ", feed.CreatedAt)
+	fmt.Println("Last fetched at: ", feed.LastFetchedAt.Time)
+	fmt.Printf("Last fetch: %d inserted, %d updated, %d skipped\n", feed.LastPostsInserted, feed.LastPostsUpdated, feed.LastPostsSkipped)
+	fmt.Println("Last attempted at: ", feed.LastAttemptedAt.Time)
+	fmt.Println("Status: ", feed.FetchStatus.String)
+	fmt.Println("Retention: ", feedRetention(s.cfg, feed))
+	return nil
+}
+
+func handlerFollow(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return errors.New("command arg's slice empty")
+	}
+
+	feed, err := findFollowableFeed(s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
 	myParams := database.CreateFeedFollowParams{
 		ID:     uuid.New(),
 		UserID: user.ID,
@@ -284,7 +505,7 @@ func handlerUnfollow(s *state, cmd command, user database.User) error {
 		return errors.New("command arg's slice empty")
 	}
 
-	feed, err := s.db.GetFeedByUrl(context.Background(), cmd.Args[0])
+	feed, err := getFeedByURL(s, cmd.Args[0])
 	if err != nil {
 		return fmt.Errorf("failed to get feed bu url: %w", err)
 	}
@@ -301,20 +522,30 @@ func handlerUnfollow(s *state, cmd command, user database.User) error {
 }
 
 func handlerBrowse(s *state, cmd command, user database.User) error {
+	flags := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	author := flags.String("author", "", "only show posts by this author")
+	category := flags.String("category", "", "only show posts in this category")
+	full := flags.Bool("full", false, "print the full content when the feed provides it")
+	err := flags.Parse(cmd.Args)
+	if err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
 	var limit int
-	err := errors.New("no input arguments")
-	if len(cmd.Args) > 0 {
-		limit, err = strconv.Atoi(cmd.Args[0])
+	err = errors.New("no input arguments")
+	if flags.NArg() > 0 {
+		limit, err = strconv.Atoi(flags.Arg(0))
 	}
 	if err != nil {
-		println("No proper limit number of posts provided, defaulting to 2")
-		print("\n")
+		slog.Warn("no valid post limit given, defaulting to 2")
 		limit = 2
 	}
 
 	myParams := database.GetPostsforUserParams{
-		Name:  user.Name,
-		Limit: int32(limit),
+		Name:     user.Name,
+		Author:   nullString(*author),
+		Category: nullString(*category),
+		Limit:    int32(limit),
 	}
 
 	posts, err := s.db.GetPostsforUser(context.Background(), myParams)
@@ -328,8 +559,16 @@ func handlerBrowse(s *state, cmd command, user database.User) error {
 		fmt.Println("Updated at: ", post.UpdatedAt)
 		fmt.Println("Published at: ", post.PublishedAt)
 		fmt.Println("Url: ", post.Url)
-		fmt.Println("Description: ", post.Description)
-		print("\n")
+		if post.ResolvedUrl.Valid {
+			fmt.Println("Resolved url: ", post.ResolvedUrl.String)
+		}
+		fmt.Println("Author: ", post.Author.String)
+		if *full && post.Content.Valid {
+			fmt.Println("Content: ", post.Content.String)
+		} else {
+			fmt.Println("Description: ", post.Description)
+		}
+		fmt.Println()
 	}
 	return nil
 }
@@ -349,46 +588,282 @@ func middlewareLoggedIn(handler func(s *state, cmd command, user database.User)
 	}
 }
 
-func scrapeFeeds(s *state) error {
-	feed, err := s.db.GetNextFeedToFetch(context.Background())
+func setFeedHeaders(s *state, feedID uuid.UUID, headers map[string]string) error {
+	params, err := feedHeadersParams(feedID, headers)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.SetFeedHeaders(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("failed to store the feed headers: %w", err)
+	}
+	return nil
+}
+
+func feedHeadersParams(feedID uuid.UUID, headers map[string]string) (database.SetFeedHeadersParams, error) {
+	data, err := json.Marshal(headers)
 	if err != nil {
-		return fmt.Errorf("failed to get the next feed to fetch: %w", err)
+		return database.SetFeedHeadersParams{}, fmt.Errorf("failed to encode the feed headers: %w", err)
 	}
+	return database.SetFeedHeadersParams{
+		ID:      feedID,
+		Headers: data,
+	}, nil
+}
 
-	err = s.db.MarkFeedFetched(context.Background(), feed.ID)
+// getFeedByURL looks up a feed by the canonical form of feedURL.
+func getFeedByURL(s *state, feedURL string) (database.Feed, error) {
+	canonicalURL, err := canonical.URL(feedURL)
 	if err != nil {
-		return fmt.Errorf("failed to mark the fetched feed: %w", err)
+		return database.Feed{}, fmt.Errorf("failed to canonicalise url: %w", err)
 	}
+	return s.db.GetFeedByUrl(context.Background(), canonicalURL)
+}
 
-	rssFeed, err := fetchFeed(context.Background(), feed.Url)
+// findFollowableFeed looks up a known feed by url, falling back to the feeds
+// discovered on that page when the url is a website rather than a feed.
+func findFollowableFeed(s *state, pageURL string) (database.Feed, error) {
+	feed, err := getFeedByURL(s, pageURL)
+	if err == nil {
+		return feed, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.Feed{}, fmt.Errorf("failed to get feed by url: %w", err)
+	}
+
+	candidates, err := s.fetcher.discoverFeeds(context.Background(), pageURL, fetchOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to fetch the feed: %w", err)
+		return database.Feed{}, fmt.Errorf("failed to discover feeds: %w", err)
 	}
 
-	for _, item := range rssFeed.Channel.Item {
-		publishTime, err := time.Parse(time.RFC1123Z, item.PubDate)
+	var known []database.Feed
+	for _, candidate := range candidates {
+		feed, err := getFeedByURL(s, candidate)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
 		if err != nil {
-			return fmt.Errorf("failed to parse PubDate: %w", err)
+			return database.Feed{}, fmt.Errorf("failed to get feed by url: %w", err)
+		}
+		known = append(known, feed)
+	}
+
+	switch len(known) {
+	case 0:
+		if len(candidates) == 0 {
+			return database.Feed{}, fmt.Errorf("no feed found at %s", pageURL)
 		}
-		description := sql.NullString{
-			String: item.Description,
-			Valid:  item.Description != "", // or some other check for "is it meaningful?"
+		return database.Feed{}, fmt.Errorf("no known feed at %s, add one with addfeed:\n  %s", pageURL, strings.Join(candidates, "\n  "))
+	case 1:
+		fmt.Println("Using feed found at: ", known[0].Url)
+		return known[0], nil
+	default:
+		urls := make([]string, len(known))
+		for i, feed := range known {
+			urls[i] = feed.Url
 		}
+		_, err := pickFeed(pageURL, urls)
+		return database.Feed{}, err
+	}
+}
+
+// scrapeFeeds fetches the feeds due next, several at once, and returns how
+// many were fetched successfully. Feeds sharing a host are spaced out by the
+// fetcher.
+func scrapeFeeds(s *state) (int, error) {
+	timer := prometheus.NewTimer(scrapeRoundDuration)
+	defer timer.ObserveDuration()
+
+	lag, err := s.db.GetSchedulerLag(context.Background())
+	if err != nil {
+		dbErrors.WithLabelValues("scheduler_lag").Inc()
+		slog.Error("failed to get the scheduler lag", "error", err)
+	} else {
+		schedulerLag.Set(lag)
+	}
+
+	feeds, err := s.db.GetNextFeedsToFetch(context.Background(), int32(s.cfg.Concurrency()))
+	if err != nil {
+		dbErrors.WithLabelValues("next_feeds").Inc()
+		return 0, fmt.Errorf("failed to get the next feeds to fetch: %w", err)
+	}
+
+	fetched := 0
+	var errs []error
+	for _, outcome := range fetchFeeds(s, feeds) {
+		log := feedLogger(outcome.Fetch.FeedID, outcome.Feed.Url).With("duration", outcome.Duration)
+		if outcome.Err != nil {
+			log.Error("failed to fetch feed", "error", outcome.Err)
+			errs = append(errs, outcome.Err)
+			continue
+		}
+		fetched++
+		log.Info("fetched feed",
+			"items", outcome.Fetch.ItemsSeen,
+			"inserted", outcome.Fetch.PostsInserted,
+			"updated", outcome.Fetch.PostsUpdated,
+		)
+	}
+
+	pruneFeedFetches(s)
+	return fetched, errors.Join(errs...)
+}
+
+// feedOutcome is how fetching one feed went.
+type feedOutcome struct {
+	Feed     database.Feed
+	Fetch    database.CreateFeedFetchParams
+	Duration time.Duration
+	Err      error
+}
+
+// fetchFeeds fetches and stores the feeds, as many at once as the configured
+// concurrency, recording the status and history of each.
+func fetchFeeds(s *state, feeds []database.Feed) []feedOutcome {
+	outcomes := make([]feedOutcome, len(feeds))
+	slots := make(chan struct{}, s.cfg.Concurrency())
+	var wg sync.WaitGroup
+	for i, feed := range feeds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			fetch := newFeedFetch(feed.ID)
+			err := scrapeFeed(s, feed, &fetch)
+			setFetchStatus(s, fetch.FeedID, err)
+			recordFeedFetch(s, fetch, err)
+			feedFetches.WithLabelValues(fetchStatus(err)).Inc()
+			postsInserted.Add(float64(fetch.PostsInserted))
+			postsUpdated.Add(float64(fetch.PostsUpdated))
+			outcomes[i] = feedOutcome{
+				Feed:     feed,
+				Fetch:    fetch,
+				Duration: time.Since(fetch.StartedAt),
+				Err:      err,
+			}
+		}()
+	}
+	wg.Wait()
+	return outcomes
+}
+
+// scrapeFeed fetches and stores one feed, filling in what fetch records
+// about it.
+func scrapeFeed(s *state, feed database.Feed, fetch *database.CreateFeedFetchParams) error {
+	log := feedLogger(feed.ID, feed.Url)
+	log.Debug("fetching feed")
+
+	// Only successful fetches mark the feed fetched, the attempt moves it to
+	// the back of the queue either way.
+	err := s.db.MarkFeedAttempted(context.Background(), feed.ID)
+	if err != nil {
+		dbErrors.WithLabelValues("mark_attempted").Inc()
+		return fmt.Errorf("failed to mark the feed attempted: %w", err)
+	}
+
+	opts, err := feedFetchOptions(s.cfg, feed)
+	if err != nil {
+		return err
+	}
 
-		myParams := database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-			Title:       item.Title,
-			Url:         item.Link,
-			Description: description,
-			PublishedAt: publishTime,
-			FeedID:      feed.ID,
+	result, err := s.fetcher.fetchFeed(context.Background(), feed.Url, opts)
+	fetch.StatusCode = sql.NullInt32{
+		Int32: int32(result.StatusCode),
+		Valid: result.StatusCode != 0,
+	}
+	fetch.Bytes = result.Bytes
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && !statusErr.RetryAt.IsZero() {
+		// Keep the whole host out of the next rounds, across restarts too.
+		cooldownErr := s.db.SetHostCooldown(context.Background(), database.SetHostCooldownParams{
+			Host:  hostOf(feed.Url),
+			Until: statusErr.RetryAt,
+		})
+		if cooldownErr != nil {
+			dbErrors.WithLabelValues("host_cooldown").Inc()
+			log.Error("failed to record the host cooldown", "host", hostOf(feed.Url), "error", cooldownErr)
 		}
-		err = s.db.CreatePost(context.Background(), myParams)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch the feed %s: %w", feed.Url, err)
+	}
+	rssFeed := result.Feed
+	fetch.ItemsSeen = int32(len(rssFeed.Channel.Item))
+
+	// A consistent permanent redirect may merge this feed into another one.
+	feed.ID, err = trackFeedRedirect(s, feed, result.PermanentURL)
+	if err != nil {
+		return fmt.Errorf("failed to track the feed redirect: %w", err)
+	}
+	fetch.FeedID = feed.ID
+
+	retention := feedRetention(s.cfg, feed)
+	var resolvedLinks map[string]string
+	if s.cfg.ResolvePostLinks {
+		resolvedLinks, err = resolvePostLinks(s, log, feed.ID, rssFeed.Channel.Item, retention)
 		if err != nil {
-			return fmt.Errorf("failed to create a post: %w", err)
+			return fmt.Errorf("failed to resolve the post links: %w", err)
 		}
 	}
+
+	counts, err := ingestFeed(s, feed.ID, rssFeed, resolvedLinks, retention)
+	if err != nil {
+		dbErrors.WithLabelValues("ingest").Inc()
+		return err
+	}
+	fetch.PostsInserted = counts.LastPostsInserted
+	fetch.PostsUpdated = counts.LastPostsUpdated
 	return nil
 }
+
+// Values of feeds.fetch_status.
+const (
+	fetchStatusOK               = "ok"
+	fetchStatusError            = "error"
+	fetchStatusRobotsDisallowed = "robots_disallowed"
+)
+
+// fetchStatus is the fetch_status a fetch ending with fetchErr leaves.
+func fetchStatus(fetchErr error) string {
+	if errors.Is(fetchErr, errRobotsDisallowed) {
+		return fetchStatusRobotsDisallowed
+	} else if fetchErr != nil {
+		return fetchStatusError
+	}
+	return fetchStatusOK
+}
+
+// setFetchStatus records how the last fetch of a feed went.
+func setFetchStatus(s *state, feedID uuid.UUID, fetchErr error) {
+	err := s.db.SetFeedFetchStatus(context.Background(), database.SetFeedFetchStatusParams{
+		ID:          feedID,
+		FetchStatus: nullString(fetchStatus(fetchErr)),
+	})
+	if err != nil {
+		dbErrors.WithLabelValues("fetch_status").Inc()
+		slog.Error("failed to record the fetch status", "feed_id", feedID, "error", err)
+	}
+}
+
+// nullString maps empty strings to SQL NULL.
+func nullString(value string) sql.NullString {
+	return sql.NullString{
+		String: value,
+		Valid:  value != "",
+	}
+}
+
+func feedMetadataParams(feedID uuid.UUID, rssFeed *RSSFeed) database.UpdateFeedMetadataParams {
+	return database.UpdateFeedMetadataParams{
+		ID:           feedID,
+		ChannelTitle: nullString(rssFeed.Channel.Title),
+		SiteUrl:      nullString(rssFeed.Channel.Link),
+		Description:  nullString(rssFeed.Channel.Description),
+		Language:     nullString(rssFeed.Channel.Language),
+		ImageUrl:     nullString(rssFeed.Channel.Image.URL),
+		Generator:    nullString(rssFeed.Channel.Generator),
+	}
+}
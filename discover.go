@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// maxDiscoveryBodySize caps how much of a page is read while looking for feeds.
+const maxDiscoveryBodySize = 2 << 20
+
+// feedLinkTypes are the <link rel="alternate"> types that advertise a feed.
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// commonFeedPaths are probed on the site root when a page advertises no feed.
+var commonFeedPaths = []string{"/feed", "/rss.xml", "/atom.xml", "/rss", "/feed.json"}
+
+// discoverFeeds returns the feed URLs reachable from pageURL. If pageURL is a
+// feed itself it is returned as the only candidate. The headers and
+// credentials in opts are sent with every request, as a protected feed
+// rejects discovery without them.
+func (f *feedFetcher) discoverFeeds(ctx context.Context, pageURL string, opts fetchOptions) ([]string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url %q: %w", pageURL, err)
+	}
+
+	body, err := f.fetchPage(ctx, pageURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if looksLikeFeed(body) {
+		return []string{pageURL}, nil
+	}
+
+	candidates := feedLinks(base, body)
+	if len(candidates) > 0 {
+		return candidates, nil
+	}
+
+	for _, path := range commonFeedPaths {
+		candidate := base.ResolveReference(&url.URL{Path: path}).String()
+		body, err := f.fetchPage(ctx, candidate, opts)
+		if err != nil {
+			continue
+		}
+		if looksLikeFeed(body) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+// pickFeed returns the only candidate, or an error listing them all so the
+// user can choose one.
+func pickFeed(pageURL string, candidates []string) (string, error) {
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("no feed found at %s", pageURL)
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("several feeds found at %s, pick one of:\n  %s", pageURL, strings.Join(candidates, "\n  "))
+	}
+}
+
+func (f *feedFetcher) fetchPage(ctx context.Context, pageURL string, opts fetchOptions) ([]byte, error) {
+	response, err := f.get(ctx, pageURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching %s: %s", pageURL, response.Status)
+	}
+
+	reader, err := f.body(response)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxDiscoveryBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, nil
+}
+
+// looksLikeFeed reports whether body is an RSS, Atom or JSON Feed document.
+// Only the start is looked at, as body may be cut at maxDiscoveryBodySize.
+func looksLikeFeed(body []byte) bool {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return looksLikeJSONFeed(trimmed)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+	decoder.Strict = false
+	decoder.CharsetReader = charset.NewReaderLabel
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "rss" || start.Name.Local == "feed"
+		}
+	}
+}
+
+// looksLikeJSONFeed reads the top-level keys of a JSON object until it finds
+// a JSON Feed version.
+func looksLikeJSONFeed(body []byte) bool {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	_, err := decoder.Token()
+	if err != nil {
+		return false
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if key == "version" {
+			var version string
+			err = decoder.Decode(&version)
+			return err == nil && strings.HasPrefix(version, jsonFeedVersionPrefix)
+		}
+		var skipped json.RawMessage
+		err = decoder.Decode(&skipped)
+		if err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// feedLinks collects the feeds advertised by <link rel="alternate"> tags,
+// resolved against base.
+func feedLinks(base *url.URL, body []byte) []string {
+	var links []string
+	seen := make(map[string]bool)
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return links
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		if token.Data != "link" {
+			continue
+		}
+
+		var rel, linkType, href string
+		for _, attr := range token.Attr {
+			switch strings.ToLower(attr.Key) {
+			case "rel":
+				rel = strings.ToLower(attr.Val)
+			case "type":
+				linkType = strings.ToLower(strings.TrimSpace(attr.Val))
+			case "href":
+				href = strings.TrimSpace(attr.Val)
+			}
+		}
+		if !isAlternate(rel) || !feedLinkTypes[linkType] || href == "" {
+			continue
+		}
+
+		ref, err := url.Parse(href)
+		if err != nil {
+			continue
+		}
+		link := base.ResolveReference(ref).String()
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+}
+
+func isAlternate(rel string) bool {
+	for _, value := range strings.Fields(rel) {
+		if value == "alternate" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/LouisRemes-95/gator/internal/database"
+)
+
+const (
+	defaultStaleDays = 30
+	slowestHostCount = 5
+)
+
+// doctorReport is the health of every feed, grouped by problem.
+type doctorReport struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	StaleDays   int           `json:"stale_days"`
+	Checks      []doctorCheck `json:"checks"`
+}
+
+// doctorCheck is one kind of problem, the feeds or hosts showing it and what
+// to do about it.
+type doctorCheck struct {
+	Name     string          `json:"name"`
+	Title    string          `json:"title"`
+	Action   string          `json:"action"`
+	Findings []doctorFinding `json:"findings"`
+}
+
+type doctorFinding struct {
+	Name   string `json:"name"`
+	URL    string `json:"url,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func doctorCommands() *commands {
+	doctorCommands := &commands{
+		registeredCommands: make(map[string]func(*state, command) error),
+	}
+
+	doctorCommands.register("feeds", handlerDoctorFeeds)
+
+	return doctorCommands
+}
+
+func handlerDoctor(s *state, cmd command) error {
+	if len(cmd.Args) == 0 {
+		return errors.New("command arg's slice empty")
+	}
+
+	return doctorCommands().run(s, command{
+		Name: cmd.Args[0],
+		Args: cmd.Args[1:],
+	})
+}
+
+func handlerDoctorFeeds(s *state, cmd command) error {
+	flags := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	staleDays := flags.Int("days", defaultStaleDays, "days without a new post before a feed is reported stale")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	err := flags.Parse(cmd.Args)
+	if err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	report, err := buildDoctorReport(s, *staleDays)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode the report: %w", err)
+		}
+		return nil
+	}
+
+	for _, check := range report.Checks {
+		fmt.Printf("%s (%d)\n", check.Title, len(check.Findings))
+		if len(check.Findings) == 0 {
+			fmt.Println("")
+			continue
+		}
+		for _, finding := range check.Findings {
+			fmt.Printf("  %s %s %s\n", finding.Name, finding.URL, finding.Detail)
+		}
+		fmt.Println("Suggested action: ", check.Action)
+		fmt.Println("")
+	}
+	return nil
+}
+
+func buildDoctorReport(s *state, staleDays int) (doctorReport, error) {
+	now := time.Now()
+	window := time.Duration(staleDays) * 24 * time.Hour
+	report := doctorReport{
+		GeneratedAt: now,
+		StaleDays:   staleDays,
+	}
+
+	neverFetched, err := s.db.GetNeverFetchedFeeds(context.Background())
+	if err != nil {
+		return report, fmt.Errorf("failed to get the feeds never fetched: %w", err)
+	}
+	check := doctorCheck{
+		Name:     "never_fetched",
+		Title:    "Feeds never fetched",
+		Action:   "make sure agg is running; feeds attempted but never fetched are listed under failing feeds too",
+		Findings: []doctorFinding{},
+	}
+	for _, feed := range neverFetched {
+		detail := "never attempted"
+		if feed.LastAttemptedAt.Valid {
+			detail = "last attempted " + feed.LastAttemptedAt.Time.Format(time.RFC3339)
+		}
+		check.Findings = append(check.Findings, doctorFinding{
+			Name:   feed.Name,
+			URL:    feed.Url,
+			Detail: detail,
+		})
+	}
+	report.Checks = append(report.Checks, check)
+
+	failing, err := s.db.GetFailingFeeds(context.Background())
+	if err != nil {
+		return report, fmt.Errorf("failed to get the failing feeds: %w", err)
+	}
+	check = doctorCheck{
+		Name:     "failing",
+		Title:    "Feeds failing",
+		Action:   "check the error with \"feed history <url>\"; fix the URL with addfeed, set credentials with \"feed auth\", or remove the feed",
+		Findings: []doctorFinding{},
+	}
+	for _, feed := range failing {
+		check.Findings = append(check.Findings, doctorFinding{
+			Name:   feed.Name,
+			URL:    feed.Url,
+			Detail: fmt.Sprintf("%s, %d failures in a row: %s", feed.FetchStatus.String, feed.ConsecutiveFailures, feed.LastError.String),
+		})
+	}
+	report.Checks = append(report.Checks, check)
+
+	stale, err := s.db.GetStaleFeeds(context.Background(), now.Add(-window))
+	if err != nil {
+		return report, fmt.Errorf("failed to get the stale feeds: %w", err)
+	}
+	check = doctorCheck{
+		Name:     "stale",
+		Title:    fmt.Sprintf("Feeds without new posts in %d days", staleDays),
+		Action:   "the site may have moved its feed; run addfeed on the site URL to discover it again, or unfollow the feed",
+		Findings: []doctorFinding{},
+	}
+	for _, feed := range stale {
+		detail := "no posts"
+		if feed.LastPublishedAt.Valid {
+			detail = "last post " + feed.LastPublishedAt.Time.Format(time.RFC3339)
+		}
+		check.Findings = append(check.Findings, doctorFinding{
+			Name:   feed.Name,
+			URL:    feed.Url,
+			Detail: detail,
+		})
+	}
+	report.Checks = append(report.Checks, check)
+
+	unfollowed, err := s.db.GetUnfollowedFeeds(context.Background())
+	if err != nil {
+		return report, fmt.Errorf("failed to get the unfollowed feeds: %w", err)
+	}
+	check = doctorCheck{
+		Name:     "unfollowed",
+		Title:    "Feeds without followers",
+		Action:   "follow the feed, or let \"gc\" remove it once the grace period is over",
+		Findings: []doctorFinding{},
+	}
+	for _, feed := range unfollowed {
+		check.Findings = append(check.Findings, doctorFinding{
+			Name:   feed.Name,
+			URL:    feed.Url,
+			Detail: "added " + feed.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	report.Checks = append(report.Checks, check)
+
+	duplicates, err := s.db.GetDuplicateFeeds(context.Background())
+	if err != nil {
+		return report, fmt.Errorf("failed to get the duplicate feeds: %w", err)
+	}
+	check = doctorCheck{
+		Name:     "duplicates",
+		Title:    "Feeds that look like duplicates",
+		Action:   "keep one feed of each pair, they share a site and a title",
+		Findings: []doctorFinding{},
+	}
+	for _, pair := range duplicates {
+		check.Findings = append(check.Findings, doctorFinding{
+			Name:   pair.FeedName,
+			URL:    pair.FeedUrl,
+			Detail: "same as " + pair.DuplicateUrl,
+		})
+	}
+	report.Checks = append(report.Checks, check)
+
+	hosts, err := s.db.GetSlowestHosts(context.Background(), database.GetSlowestHostsParams{
+		StartedAt: now.Add(-window),
+		Limit:     slowestHostCount,
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to get the slowest hosts: %w", err)
+	}
+	check = doctorCheck{
+		Name:     "slowest_hosts",
+		Title:    "Slowest hosts",
+		Action:   "raise fetch_timeout if these hosts time out, or lower fetch_concurrency to be gentler with them",
+		Findings: []doctorFinding{},
+	}
+	for _, host := range hosts {
+		check.Findings = append(check.Findings, doctorFinding{
+			Name:   host.Host,
+			Detail: fmt.Sprintf("%d fetches, %s on average, %s at most", host.Fetches, time.Duration(host.AvgDurationMs)*time.Millisecond, time.Duration(host.MaxDurationMs)*time.Millisecond),
+		})
+	}
+	report.Checks = append(report.Checks, check)
+
+	return report, nil
+}
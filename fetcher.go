@@ -0,0 +1,280 @@
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LouisRemes-95/gator/internal/config"
+	"github.com/LouisRemes-95/gator/internal/database"
+	"github.com/andybalholm/brotli"
+)
+
+// Fetch errors callers can tell apart with errors.Is.
+var (
+	errConnectTimeout = errors.New("connect timed out")
+	errFetchTimeout   = errors.New("fetch timed out")
+	errBodyTooLarge   = errors.New("response body too large")
+)
+
+// feedFetcher performs every outgoing request over one tuned transport, with
+// the timeouts, body size limit, User-Agent and proxy from the config, and
+// spaces out requests to the same host. robots is nil unless robots.txt is
+// respected.
+type feedFetcher struct {
+	client       *http.Client
+	maxBodyBytes int64
+	userAgent    string
+	hosts        *hostLimiter
+	robots       *robotsChecker
+	failedLinks  *linkFailures
+}
+
+// fetchOptions are the per-feed settings applied to a request.
+type fetchOptions struct {
+	Headers map[string]string
+	Auth    *feedAuth
+}
+
+// feedFetchOptions returns the fetch options stored with a feed, decrypting
+// its credentials.
+func feedFetchOptions(cfg *config.Config, feed database.Feed) (fetchOptions, error) {
+	headers, err := feedHeaders(feed)
+	if err != nil {
+		return fetchOptions{}, err
+	}
+
+	auth, err := openFeedAuth(cfg, feed.AuthType, feed.AuthSecret)
+	if err != nil {
+		return fetchOptions{}, err
+	}
+
+	return fetchOptions{
+		Headers: headers,
+		Auth:    auth,
+	}, nil
+}
+
+// feedHeaders returns the extra request headers stored with a feed.
+func feedHeaders(feed database.Feed) (headerFlag, error) {
+	headers := headerFlag{}
+	if len(feed.Headers) > 0 {
+		err := json.Unmarshal(feed.Headers, &headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the feed headers: %w", err)
+		}
+	}
+	return headers, nil
+}
+
+// headerFlag collects repeated "Name: value" flags into a header map.
+type headerFlag map[string]string
+
+func (h headerFlag) String() string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func (h headerFlag) Set(value string) error {
+	name, headerValue, ok := strings.Cut(value, ":")
+	name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+	if !ok || name == "" {
+		return fmt.Errorf("header %q is not in the \"Name: value\" form", value)
+	}
+	h[name] = strings.TrimSpace(headerValue)
+	return nil
+}
+
+func newFeedFetcher(cfg *config.Config) *feedFetcher {
+	dialer := &net.Dialer{
+		Timeout:   cfg.ConnectTimeout(),
+		KeepAlive: 30 * time.Second,
+	}
+	proxy := http.ProxyFromEnvironment
+	if proxyURL := cfg.Proxy(); proxyURL != nil {
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   cfg.ConnectTimeout(),
+		ResponseHeaderTimeout: cfg.Timeout(),
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	fetcher := &feedFetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout(),
+		},
+		maxBodyBytes: cfg.MaxBodyBytes(),
+		userAgent:    cfg.UserAgent(),
+		hosts:        newHostLimiter(cfg.HostRate()),
+		failedLinks:  newLinkFailures(),
+	}
+	if cfg.RespectRobotsTxt {
+		fetcher.robots = newRobotsChecker()
+	}
+	return fetcher
+}
+
+// get sends a GET request for rawURL. The caller must close the body.
+func (f *feedFetcher) get(ctx context.Context, rawURL string, opts fetchOptions) (*http.Response, error) {
+	request, err := f.newGetRequest(ctx, rawURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	return f.do(f.client, request)
+}
+
+// newGetRequest builds a GET request for rawURL once robots.txt allows it.
+func (f *feedFetcher) newGetRequest(ctx context.Context, rawURL string, opts fetchOptions) (*http.Request, error) {
+	err := f.checkRobots(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	request.Header.Set("User-Agent", f.userAgent)
+	for name, value := range opts.Headers {
+		request.Header.Set(name, value)
+	}
+	if opts.Auth != nil {
+		opts.Auth.apply(request)
+	}
+	// Setting Accept-Encoding ourselves turns off the transport's transparent
+	// gzip support, body decompresses every encoding we advertise.
+	request.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	return request, nil
+}
+
+// do sends request once its host allows it, and makes the host cool down
+// when the response asks us to back off.
+func (f *feedFetcher) do(client *http.Client, request *http.Request) (*http.Response, error) {
+	err := f.wait(request)
+	if err != nil {
+		return nil, err
+	}
+	return f.send(client, request)
+}
+
+// wait blocks until the host of request allows another request.
+func (f *feedFetcher) wait(request *http.Request) error {
+	return f.hosts.wait(request.Context(), request.URL.String())
+}
+
+// send sends request right away, making the host cool down when the response
+// asks us to back off.
+func (f *feedFetcher) send(client *http.Client, request *http.Request) (*http.Response, error) {
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to do the request: %w", classifyFetchError(err))
+	}
+
+	if until := retryAt(response); !until.IsZero() {
+		f.hosts.coolDown(hostOf(response.Request.URL.String()), until)
+	}
+	return response, nil
+}
+
+// body returns the decompressed response body. Reading it fails with
+// errBodyTooLarge once either the compressed or the decompressed stream
+// exceeds the configured limit.
+func (f *feedFetcher) body(response *http.Response) (io.Reader, error) {
+	if response.ContentLength > f.maxBodyBytes {
+		return nil, fmt.Errorf("%w: %d bytes announced, limit is %d", errBodyTooLarge, response.ContentLength, f.maxBodyBytes)
+	}
+
+	var reader io.Reader = newLimitedReader(response.Body, f.maxBodyBytes)
+	switch strings.ToLower(strings.TrimSpace(response.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+		return reader, nil
+	case "gzip", "x-gzip":
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip body: %w", classifyFetchError(err))
+		}
+		reader = gzipReader
+	case "deflate":
+		zlibReader, err := zlib.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read deflate body: %w", classifyFetchError(err))
+		}
+		reader = zlibReader
+	case "br":
+		reader = brotli.NewReader(reader)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", response.Header.Get("Content-Encoding"))
+	}
+	return newLimitedReader(reader, f.maxBodyBytes), nil
+}
+
+// limitedReader is io.LimitReader failing with errBodyTooLarge instead of
+// stopping silently, and reporting timeouts like the rest of the fetcher.
+type limitedReader struct {
+	reader    io.Reader
+	limit     int64
+	remaining int64
+}
+
+func newLimitedReader(reader io.Reader, limit int64) *limitedReader {
+	return &limitedReader{
+		reader:    reader,
+		limit:     limit,
+		remaining: limit,
+	}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, fmt.Errorf("%w: limit is %d bytes", errBodyTooLarge, l.limit)
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), fmt.Errorf("%w: limit is %d bytes", errBodyTooLarge, l.limit)
+	}
+	if err != nil && err != io.EOF {
+		return n, classifyFetchError(err)
+	}
+	return n, err
+}
+
+// classifyFetchError wraps timeouts in errConnectTimeout or errFetchTimeout.
+func classifyFetchError(err error) error {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" && opErr.Timeout() {
+		return fmt.Errorf("%w: %w", errConnectTimeout, err)
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", errFetchTimeout, err)
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// handlerGC removes the feeds nobody has followed for longer than the grace
+// period, along with the posts no other feed links to. With --archive the
+// feeds and posts are kept but the feeds are marked archived.
+func handlerGC(s *state, cmd command) error {
+	flags := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	grace := flags.Duration("grace", s.cfg.OrphanGrace(), "how long a feed must have had no follower")
+	archive := flags.Bool("archive", false, "archive the feeds instead of deleting them")
+	dryRun := flags.Bool("dry-run", false, "only list the feeds that would be collected")
+	err := flags.Parse(cmd.Args)
+	if err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	feeds, err := s.db.GetOrphanedFeeds(context.Background(), sql.NullTime{
+		Time:  time.Now().Add(-*grace),
+		Valid: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get the orphaned feeds: %w", err)
+	}
+
+	tx, err := s.sqlDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	collected := 0
+	for _, feed := range feeds {
+		if *archive && feed.ArchivedAt.Valid {
+			continue
+		}
+		collected++
+
+		fmt.Printf("%s %s, no follower since %s\n", feed.Name, feed.Url, feed.OrphanedAt.Time.Format(time.RFC3339))
+		if *dryRun {
+			continue
+		}
+
+		if *archive {
+			err = qtx.ArchiveFeed(context.Background(), feed.ID)
+			if err != nil {
+				return fmt.Errorf("failed to archive the feed %s: %w", feed.Url, err)
+			}
+		} else {
+			err = qtx.DeleteFeed(context.Background(), feed.ID)
+			if err != nil {
+				return fmt.Errorf("failed to delete the feed %s: %w", feed.Url, err)
+			}
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("%d feeds would be collected\n", collected)
+		return nil
+	}
+
+	var posts int64
+	if !*archive {
+		posts, err = qtx.DeleteOrphanedPosts(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to delete the orphaned posts: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	if *archive {
+		fmt.Printf("%d feeds archived\n", collected)
+	} else {
+		fmt.Printf("%d feeds and %d posts deleted\n", collected, posts)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// minRoundGrace keeps short intervals from flapping the checks while a
+	// round waits on slow hosts.
+	minRoundGrace    = 5 * time.Minute
+	roundGraceFactor = 3
+	healthDBTimeout  = 2 * time.Second
+)
+
+// aggHealth tracks the progress of the agg loop for /healthz and /readyz.
+// The HTTP handlers only read what the loop records here, never the config
+// a SIGHUP may be replacing.
+type aggHealth struct {
+	mu          sync.Mutex
+	grace       time.Duration
+	lagLimit    time.Duration
+	startedAt   time.Time
+	lastRound   time.Time
+	lastSuccess time.Time
+}
+
+// healthReport is the body of /healthz and /readyz.
+type healthReport struct {
+	Status string        `json:"status"`
+	Checks []healthCheck `json:"checks"`
+}
+
+type healthCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// newAggHealth expects a round every interval, allowing for rounds to run
+// late by a few intervals, and no feed to wait longer than lagLimit.
+func newAggHealth(interval, lagLimit time.Duration) *aggHealth {
+	return &aggHealth{
+		grace:     max(roundGraceFactor*interval, minRoundGrace),
+		lagLimit:  lagLimit,
+		startedAt: time.Now(),
+	}
+}
+
+// setLagLimit applies a reloaded max_scheduler_lag.
+func (h *aggHealth) setLagLimit(lagLimit time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lagLimit = lagLimit
+}
+
+// roundDone records the end of a round and whether it made progress.
+func (h *aggHealth) roundDone(ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastRound = time.Now()
+	if ok {
+		h.lastSuccess = h.lastRound
+	}
+}
+
+// handleLive reports whether the loop still completes rounds, so a stuck agg
+// gets restarted.
+func (h *aggHealth) handleLive(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	lastRound := h.lastRound
+	h.mu.Unlock()
+
+	check := healthCheck{
+		Name: "loop",
+	}
+	if lastRound.IsZero() {
+		check.OK = time.Since(h.startedAt) <= h.grace
+		check.Detail = fmt.Sprintf("first round running for %s", time.Since(h.startedAt).Round(time.Second))
+	} else {
+		check.OK = time.Since(lastRound) <= h.grace
+		check.Detail = fmt.Sprintf("last round finished %s ago", time.Since(lastRound).Round(time.Second))
+	}
+	writeHealth(w, []healthCheck{check})
+}
+
+// handleReady reports whether agg is keeping the feeds up to date: the
+// database answers, a recent round made progress and no feed waits too long.
+func (h *aggHealth) handleReady(s *state, w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthDBTimeout)
+	defer cancel()
+
+	var checks []healthCheck
+
+	database := healthCheck{
+		Name: "database",
+		OK:   true,
+	}
+	err := s.sqlDB.PingContext(ctx)
+	if err != nil {
+		database.OK = false
+		database.Detail = err.Error()
+	}
+	checks = append(checks, database)
+
+	h.mu.Lock()
+	lastSuccess := h.lastSuccess
+	limit := h.lagLimit
+	h.mu.Unlock()
+	scrape := healthCheck{
+		Name:   "scrape",
+		OK:     !lastSuccess.IsZero() && time.Since(lastSuccess) <= h.grace,
+		Detail: "no successful round yet",
+	}
+	if !lastSuccess.IsZero() {
+		scrape.Detail = fmt.Sprintf("last successful round %s ago", time.Since(lastSuccess).Round(time.Second))
+	}
+	checks = append(checks, scrape)
+
+	backlog := healthCheck{
+		Name: "backlog",
+	}
+	if database.OK {
+		lag, err := s.db.GetSchedulerLag(ctx)
+		if err != nil {
+			dbErrors.WithLabelValues("scheduler_lag").Inc()
+			backlog.Detail = err.Error()
+		} else {
+			wait := time.Duration(lag * float64(time.Second)).Round(time.Second)
+			backlog.OK = wait <= limit
+			backlog.Detail = fmt.Sprintf("oldest feed waiting %s, limit %s", wait, limit)
+		}
+	} else {
+		backlog.Detail = "database unavailable"
+	}
+	checks = append(checks, backlog)
+
+	writeHealth(w, checks)
+}
+
+// writeHealth answers 200 when every check passes and 503 otherwise.
+func writeHealth(w http.ResponseWriter, checks []healthCheck) {
+	report := healthReport{
+		Status: "ok",
+		Checks: checks,
+	}
+	status := http.StatusOK
+	for _, check := range checks {
+		if !check.OK {
+			report.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(report)
+	if err != nil {
+		slog.Error("failed to write the health report", "error", err)
+	}
+}
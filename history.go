@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/LouisRemes-95/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const defaultHistoryLimit = 20
+
+// newFeedFetch starts the history record of a fetch of the feed.
+func newFeedFetch(feedID uuid.UUID) database.CreateFeedFetchParams {
+	return database.CreateFeedFetchParams{
+		ID:        uuid.New(),
+		FeedID:    feedID,
+		StartedAt: time.Now(),
+	}
+}
+
+// recordFeedFetch completes and stores the history record of a fetch.
+func recordFeedFetch(s *state, fetch database.CreateFeedFetchParams, fetchErr error) {
+	fetch.FinishedAt = time.Now()
+	fetch.DurationMs = fetch.FinishedAt.Sub(fetch.StartedAt).Milliseconds()
+	if fetchErr != nil {
+		fetch.Error = nullString(fetchErr.Error())
+	}
+
+	err := s.db.CreateFeedFetch(context.Background(), fetch)
+	if err != nil {
+		dbErrors.WithLabelValues("record_fetch").Inc()
+		slog.Error("failed to record the fetch", "feed_id", fetch.FeedID, "error", err)
+	}
+}
+
+// pruneFeedFetches drops the history older than the configured retention.
+func pruneFeedFetches(s *state) {
+	cutoff := time.Now().Add(-s.cfg.FetchHistoryRetention())
+	_, err := s.db.DeleteFeedFetchesBefore(context.Background(), cutoff)
+	if err != nil {
+		dbErrors.WithLabelValues("prune_fetches").Inc()
+		slog.Error("failed to prune the fetch history", "error", err)
+	}
+}
+
+func handlerFeedHistory(s *state, cmd command) error {
+	flags := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	limit := flags.Int("limit", defaultHistoryLimit, "number of fetches to show")
+	err := flags.Parse(cmd.Args)
+	if err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+	args := flags.Args()
+
+	if len(args) == 0 {
+		return errors.New("usage: feed history [--limit n] <url>")
+	}
+
+	feed, err := getFeedByURL(s, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get feed by url: %w", err)
+	}
+
+	fetches, err := s.db.GetFeedFetches(context.Background(), database.GetFeedFetchesParams{
+		FeedID: feed.ID,
+		Limit:  int32(*limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get the fetch history: %w", err)
+	}
+
+	if len(fetches) == 0 {
+		fmt.Println("No fetch recorded for", feed.Url)
+		return nil
+	}
+
+	for _, fetch := range fetches {
+		fmt.Println("Started at: ", fetch.StartedAt)
+		fmt.Println("Duration: ", time.Duration(fetch.DurationMs)*time.Millisecond)
+		fmt.Println("Status: ", statusLabel(fetch.StatusCode))
+		fmt.Println("Bytes: ", fetch.Bytes)
+		fmt.Printf("Items: %d seen, %d inserted, %d updated\n", fetch.ItemsSeen, fetch.PostsInserted, fetch.PostsUpdated)
+		if fetch.Error.Valid {
+			fmt.Println("Error: ", fetch.Error.String)
+		}
+		fmt.Println("")
+	}
+	return nil
+}
+
+func statusLabel(statusCode sql.NullInt32) string {
+	if !statusCode.Valid {
+		return "no response"
+	}
+	return fmt.Sprint(statusCode.Int32)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Bounds on how long a host asking us to back off is left alone.
+const (
+	defaultRetryAfter = time.Minute
+	maxRetryAfter     = 6 * time.Hour
+)
+
+// errHostCoolingDown is returned for requests to a host that answered 429 or
+// 503 and has not reached its Retry-After time yet.
+var errHostCoolingDown = errors.New("host is cooling down")
+
+// statusError is an unexpected response status. RetryAt is set when the
+// server asked us to back off.
+type statusError struct {
+	Status  string
+	RetryAt time.Time
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status: %s", e.Status)
+}
+
+// newStatusError describes a non 200 response.
+func newStatusError(response *http.Response) *statusError {
+	return &statusError{
+		Status:  response.Status,
+		RetryAt: retryAt(response),
+	}
+}
+
+// retryAt returns when the host may be asked again after a 429 or 503, and
+// the zero time for any other response.
+func retryAt(response *http.Response) time.Time {
+	if response.StatusCode != http.StatusTooManyRequests && response.StatusCode != http.StatusServiceUnavailable {
+		return time.Time{}
+	}
+	now := time.Now()
+	return now.Add(retryAfter(response.Header.Get("Retry-After"), now))
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP
+// date, falling back to defaultRetryAfter and capped at maxRetryAfter.
+func retryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	delay := defaultRetryAfter
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = date.Sub(now)
+	}
+
+	if delay <= 0 {
+		return defaultRetryAfter
+	}
+	return min(delay, maxRetryAfter)
+}
+
+// hostLimiter spaces out requests to the same host with a token bucket per
+// host, and holds them back while the host is cooling down.
+type hostLimiter struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	burst     int
+	limiters  map[string]*rate.Limiter
+	cooldowns map[string]time.Time
+}
+
+func newHostLimiter(interval time.Duration, burst int) *hostLimiter {
+	return &hostLimiter{
+		interval:  interval,
+		burst:     burst,
+		limiters:  make(map[string]*rate.Limiter),
+		cooldowns: make(map[string]time.Time),
+	}
+}
+
+// wait blocks until a request to rawURL's host may go out.
+func (h *hostLimiter) wait(ctx context.Context, rawURL string) error {
+	host := hostOf(rawURL)
+	if host == "" {
+		return nil
+	}
+
+	h.mu.Lock()
+	until := h.cooldowns[host]
+	limiter, ok := h.limiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(h.interval), h.burst)
+		h.limiters[host] = limiter
+	}
+	h.mu.Unlock()
+
+	if time.Now().Before(until) {
+		return fmt.Errorf("%w: %s until %s", errHostCoolingDown, host, until.Format(time.RFC3339))
+	}
+	return limiter.Wait(ctx)
+}
+
+// coolDown holds back requests to host until the given time.
+func (h *hostLimiter) coolDown(host string, until time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if until.After(h.cooldowns[host]) {
+		h.cooldowns[host] = until
+	}
+}
+
+// hostOf returns the lower-cased host name of rawURL, without the port. It
+// must match the host extracted in GetNextFeedsToFetch.
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"missing", "", defaultRetryAfter},
+		{"seconds", "120", 2 * time.Minute},
+		{"padded seconds", " 30 ", 30 * time.Second},
+		{"zero seconds", "0", defaultRetryAfter},
+		{"negative seconds", "-5", defaultRetryAfter},
+		{"capped seconds", "86400", maxRetryAfter},
+		{"date", now.Add(10 * time.Minute).Format(http.TimeFormat), 10 * time.Minute},
+		{"past date", now.Add(-time.Hour).Format(http.TimeFormat), defaultRetryAfter},
+		{"capped date", now.Add(48 * time.Hour).Format(http.TimeFormat), maxRetryAfter},
+		{"garbage", "soon", defaultRetryAfter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := retryAfter(tt.value, now)
+			if got != tt.want {
+				t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	httpReadHeaderTimeout = 5 * time.Second
+	httpShutdownTimeout   = 5 * time.Second
+)
+
+// aggServer serves the endpoints agg exposes while it runs.
+type aggServer struct {
+	server *http.Server
+}
+
+// startAggServer listens on addr and serves /metrics, /healthz and /readyz
+// in the background.
+func startAggServer(s *state, health *aggHealth, addr string) (*aggServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+	mux.HandleFunc("GET /healthz", health.handleLive)
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		health.handleReady(s, w, r)
+	})
+
+	srv := &aggServer{
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: httpReadHeaderTimeout,
+		},
+	}
+	go func() {
+		err := srv.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("http server failed", "addr", addr, "error", err)
+		}
+	}()
+	slog.Info("http server listening", "addr", listener.Addr().String())
+	return srv, nil
+}
+
+// stop lets the requests in flight finish before closing the server.
+func (srv *aggServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	err := srv.server.Shutdown(ctx)
+	if err != nil {
+		slog.Error("failed to shut down the http server", "error", err)
+	}
+}
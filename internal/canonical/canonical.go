@@ -0,0 +1,98 @@
+package canonical
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters added by newsletters, ad networks and
+// social sites that never change the page being linked to.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"mkt_tok": true,
+}
+
+// URL returns the canonical form of rawURL used to compare feeds and posts.
+// http and https, a leading "www.", default ports, trailing slashes,
+// fragments and tracking parameters do not change the canonical form.
+//
+// The result is a comparison key rather than an address to fetch. Keep it and
+// trackingParams in sync with the backfill in sql/schema/010_canonical_urls.sql.
+func URL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", errors.New("empty url")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse url %q: %w", rawURL, err)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("url %q has no host", rawURL)
+	}
+
+	host := strings.ToLower(parsed.Host)
+	host = strings.TrimPrefix(host, "www.")
+	host = strings.TrimSuffix(host, ":80")
+	host = strings.TrimSuffix(host, ":443")
+
+	path := parsed.EscapedPath()
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	canonical := "https://" + host + path
+	if query := stripParams(parsed.RawQuery); query != "" {
+		canonical += "?" + query
+	}
+	return canonical, nil
+}
+
+// StripTracking removes tracking parameters from rawURL and leaves the rest of
+// it untouched. Unparsable URLs are returned as is.
+func StripTracking(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	parsed.RawQuery = stripParams(parsed.RawQuery)
+	return parsed.String()
+}
+
+// stripParams drops tracking parameters from a raw query, keeping the order
+// of the remaining ones.
+func stripParams(rawQuery string) string {
+	var kept []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" || isTrackingParam(param) {
+			continue
+		}
+		kept = append(kept, param)
+	}
+	return strings.Join(kept, "&")
+}
+
+// isTrackingParam reports whether a key=value pair is a tracking parameter.
+func isTrackingParam(param string) bool {
+	key, _, _ := strings.Cut(param, "=")
+	key = strings.ToLower(key)
+	return strings.HasPrefix(key, "utm_") || trackingParams[key]
+}
@@ -0,0 +1,82 @@
+package canonical
+
+import "testing"
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"https", "https://example.com/feed", "https://example.com/feed"},
+		{"http becomes https", "http://example.com/feed", "https://example.com/feed"},
+		{"no scheme", "example.com/feed", "https://example.com/feed"},
+		{"surrounding space", "  https://example.com/feed\n", "https://example.com/feed"},
+		{"host case", "https://Example.COM/Feed", "https://example.com/Feed"},
+		{"www", "https://www.example.com/feed", "https://example.com/feed"},
+		{"http port", "http://example.com:80/feed", "https://example.com/feed"},
+		{"https port", "https://example.com:443/feed", "https://example.com/feed"},
+		{"other port", "https://example.com:8080/feed", "https://example.com:8080/feed"},
+		{"trailing slash", "https://example.com/feed/", "https://example.com/feed"},
+		{"trailing slashes", "https://example.com/feed//", "https://example.com/feed"},
+		{"root", "https://example.com", "https://example.com/"},
+		{"root slash", "https://example.com/", "https://example.com/"},
+		{"fragment", "https://example.com/post#comments", "https://example.com/post"},
+		{"query kept", "https://example.com/feed?format=rss", "https://example.com/feed?format=rss"},
+		{"query order kept", "https://example.com/feed?b=2&a=1", "https://example.com/feed?b=2&a=1"},
+		{"utm", "https://example.com/post?utm_source=rss&utm_medium=feed", "https://example.com/post"},
+		{"utm case", "https://example.com/post?UTM_Source=rss&id=3", "https://example.com/post?id=3"},
+		{"utm among params", "https://example.com/post?id=3&utm_campaign=x&page=2", "https://example.com/post?id=3&page=2"},
+		{"empty params", "https://example.com/post?&id=3&", "https://example.com/post?id=3"},
+		{"fbclid", "https://example.com/post?fbclid=abc", "https://example.com/post"},
+		{"gclid", "https://example.com/post?gclid=abc", "https://example.com/post"},
+		{"dclid", "https://example.com/post?dclid=abc", "https://example.com/post"},
+		{"msclkid", "https://example.com/post?msclkid=abc", "https://example.com/post"},
+		{"yclid", "https://example.com/post?yclid=abc", "https://example.com/post"},
+		{"igshid", "https://example.com/post?igshid=abc", "https://example.com/post"},
+		{"mailchimp", "https://example.com/post?mc_cid=a&mc_eid=b", "https://example.com/post"},
+		{"hubspot", "https://example.com/post?_hsenc=a&_hsmi=b", "https://example.com/post"},
+		{"marketo", "https://example.com/post?mkt_tok=abc", "https://example.com/post"},
+		{"tracking key without value", "https://example.com/post?fbclid&id=3", "https://example.com/post?id=3"},
+		{"lookalike key kept", "https://example.com/post?fbclid2=abc", "https://example.com/post?fbclid2=abc"},
+		{"escaped path", "https://example.com/a%20b/", "https://example.com/a%20b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := URL(tt.raw)
+			if err != nil {
+				t.Fatalf("URL(%q) returned error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("URL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLErrors(t *testing.T) {
+	for _, raw := range []string{"", "   ", "https://", "http://%zz"} {
+		_, err := URL(raw)
+		if err == nil {
+			t.Errorf("URL(%q) returned no error", raw)
+		}
+	}
+}
+
+func TestStripTracking(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"http://www.example.com/post/?utm_source=rss&id=3#top", "http://www.example.com/post/?id=3#top"},
+		{"https://example.com/post?fbclid=abc", "https://example.com/post"},
+		{"https://example.com/post", "https://example.com/post"},
+		{"://bad", "://bad"},
+	}
+	for _, tt := range tests {
+		got := StripTracking(tt.raw)
+		if got != tt.want {
+			t.Errorf("StripTracking(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
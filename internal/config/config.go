@@ -1,17 +1,81 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/LouisRemes-95/gator/internal/secret"
 )
 
 const configFileName = ".gatorconfig.json"
 
+// pidFileName is where agg records its pid unless pid_file is set.
+const pidFileName = ".gator-agg.pid"
+
+// credentialsKeyEnv overrides the credentials_key setting.
+const credentialsKeyEnv = "GATOR_CREDENTIALS_KEY"
+
+// Defaults for the optional fetch settings.
+const (
+	DefaultFetchConnectTimeout = 10 * time.Second
+	DefaultFetchTimeout        = 30 * time.Second
+	DefaultFetchMaxBodyBytes   = 10 << 20
+	DefaultUserAgent           = "gator"
+	DefaultFetchConcurrency    = 4
+	DefaultHostInterval        = time.Second
+	DefaultHostBurst           = 1
+	DefaultFetchHistoryDays    = 30
+	DefaultOrphanGracePeriod   = 7 * 24 * time.Hour
+	DefaultMaxSchedulerLag     = 24 * time.Hour
+)
+
 type Config struct {
 	DbUrl           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+	// ResolvePostLinks follows redirects on post links while aggregating.
+	ResolvePostLinks bool `json:"resolve_post_links,omitempty"`
+	// Fetch limits, durations use time.ParseDuration syntax such as "10s".
+	FetchConnectTimeout string `json:"fetch_connect_timeout,omitempty"`
+	FetchTimeout        string `json:"fetch_timeout,omitempty"`
+	FetchMaxBodyBytes   int64  `json:"fetch_max_body_bytes,omitempty"`
+	// FetchUserAgent and FetchProxy apply to every outgoing request. Without
+	// a proxy the HTTP_PROXY and HTTPS_PROXY environment variables are used.
+	FetchUserAgent string `json:"user_agent,omitempty"`
+	FetchProxy     string `json:"http_proxy,omitempty"`
+	// FeedCredentialsKey is the base64 encoded 32 byte key encrypting feed
+	// credentials at rest, the GATOR_CREDENTIALS_KEY variable takes precedence.
+	FeedCredentialsKey string `json:"credentials_key,omitempty"`
+	// FetchConcurrency feeds are fetched at once by the aggregator, while each
+	// host gets at most HostBurst requests in a row, then one per HostInterval.
+	FetchConcurrency int    `json:"fetch_concurrency,omitempty"`
+	HostInterval     string `json:"host_interval,omitempty"`
+	HostBurst        int    `json:"host_burst,omitempty"`
+	// RespectRobotsTxt skips URLs the host's robots.txt disallows for our
+	// User-Agent.
+	RespectRobotsTxt bool `json:"respect_robots_txt,omitempty"`
+	// FetchHistoryDays is how long the log of every fetch is kept.
+	FetchHistoryDays int `json:"fetch_history_days,omitempty"`
+	// OrphanGracePeriod is how long a feed may have no follower before gc
+	// collects it.
+	OrphanGracePeriod string `json:"orphan_grace_period,omitempty"`
+	// Post retention applying to feeds without their own, zero keeps every
+	// post. PruneOnAgg makes the aggregator prune as it goes.
+	RetentionDays     int  `json:"retention_days,omitempty"`
+	RetentionMaxPosts int  `json:"retention_max_posts,omitempty"`
+	PruneOnAgg        bool `json:"prune_on_agg,omitempty"`
+	// AggPIDFile locks out a second agg, it defaults to ~/.gator-agg.pid.
+	AggPIDFile string `json:"pid_file,omitempty"`
+	// HTTPAddr is where agg serves /metrics, /healthz and /readyz, such as
+	// ":9090". Empty keeps the server off.
+	HTTPAddr string `json:"http_addr,omitempty"`
+	// MaxSchedulerLag is how long a feed may wait for a fetch before agg
+	// reports itself not ready.
+	MaxSchedulerLag string `json:"max_scheduler_lag,omitempty"`
 }
 
 func Read() (Config, error) {
@@ -31,9 +95,162 @@ func Read() (Config, error) {
 		return Config{}, fmt.Errorf("failed to decode config file %q: %w", filePath, err)
 	}
 
+	err = config.validate()
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid config file %q: %w", filePath, err)
+	}
+
 	return config, nil
 }
 
+// ConnectTimeout bounds dialing and the TLS handshake of a fetch.
+func (c Config) ConnectTimeout() time.Duration {
+	return durationOr(c.FetchConnectTimeout, DefaultFetchConnectTimeout)
+}
+
+// Timeout bounds a whole fetch, reading the body included.
+func (c Config) Timeout() time.Duration {
+	return durationOr(c.FetchTimeout, DefaultFetchTimeout)
+}
+
+// MaxBodyBytes is the largest response body a fetch accepts.
+func (c Config) MaxBodyBytes() int64 {
+	if c.FetchMaxBodyBytes <= 0 {
+		return DefaultFetchMaxBodyBytes
+	}
+	return c.FetchMaxBodyBytes
+}
+
+// UserAgent is sent with every outgoing request.
+func (c Config) UserAgent() string {
+	if c.FetchUserAgent == "" {
+		return DefaultUserAgent
+	}
+	return c.FetchUserAgent
+}
+
+// Proxy returns the configured proxy, or nil to use the environment.
+func (c Config) Proxy() *url.URL {
+	if c.FetchProxy == "" {
+		return nil
+	}
+	proxyURL, err := url.Parse(c.FetchProxy)
+	if err != nil {
+		return nil
+	}
+	return proxyURL
+}
+
+// Concurrency is how many feeds the aggregator fetches at once.
+func (c Config) Concurrency() int {
+	if c.FetchConcurrency <= 0 {
+		return DefaultFetchConcurrency
+	}
+	return c.FetchConcurrency
+}
+
+// HostRate returns the minimum spacing between requests to one host and how
+// many requests may go out back to back before it applies.
+func (c Config) HostRate() (time.Duration, int) {
+	burst := c.HostBurst
+	if burst <= 0 {
+		burst = DefaultHostBurst
+	}
+	return durationOr(c.HostInterval, DefaultHostInterval), burst
+}
+
+// FetchHistoryRetention is how long fetches stay in the history.
+func (c Config) FetchHistoryRetention() time.Duration {
+	days := c.FetchHistoryDays
+	if days <= 0 {
+		days = DefaultFetchHistoryDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// OrphanGrace is how long gc leaves feeds nobody follows alone.
+func (c Config) OrphanGrace() time.Duration {
+	return durationOr(c.OrphanGracePeriod, DefaultOrphanGracePeriod)
+}
+
+// SchedulerLagLimit is the longest a feed may wait for a fetch while agg
+// is ready.
+func (c Config) SchedulerLagLimit() time.Duration {
+	return durationOr(c.MaxSchedulerLag, DefaultMaxSchedulerLag)
+}
+
+// PIDFile is the path of the file agg locks and writes its pid to.
+func (c Config) PIDFile() (string, error) {
+	if c.AggPIDFile != "" {
+		return c.AggPIDFile, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home dir: %w", err)
+	}
+	return filepath.Join(homeDir, pidFileName), nil
+}
+
+// CredentialsKey returns the key encrypting feed credentials.
+func (c Config) CredentialsKey() ([]byte, error) {
+	encoded := os.Getenv(credentialsKeyEnv)
+	if encoded == "" {
+		encoded = c.FeedCredentialsKey
+	}
+	if encoded == "" {
+		return nil, fmt.Errorf("no credentials key, set %s or credentials_key in the config to the output of \"openssl rand -base64 32\"", credentialsKeyEnv)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the credentials key: %w", err)
+	}
+	if len(key) != secret.KeySize {
+		return nil, fmt.Errorf("credentials key must decode to %d bytes, got %d", secret.KeySize, len(key))
+	}
+	return key, nil
+}
+
+func (c Config) validate() error {
+	durations := map[string]string{
+		"fetch_connect_timeout": c.FetchConnectTimeout,
+		"fetch_timeout":         c.FetchTimeout,
+		"host_interval":         c.HostInterval,
+		"orphan_grace_period":   c.OrphanGracePeriod,
+		"max_scheduler_lag":     c.MaxSchedulerLag,
+	}
+	for key, value := range durations {
+		if value == "" {
+			continue
+		}
+		_, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", key, err)
+		}
+	}
+
+	if c.FetchProxy != "" {
+		proxyURL, err := url.Parse(c.FetchProxy)
+		if err != nil {
+			return fmt.Errorf("failed to parse http_proxy: %w", err)
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return fmt.Errorf("http_proxy %q needs a scheme and a host", c.FetchProxy)
+		}
+	}
+	return nil
+}
+
+// durationOr parses value, which validate has already checked, or returns
+// fallback when it is unset.
+func durationOr(value string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
 func (c *Config) SetUser(userName string) error {
 	c.CurrentUserName = userName
 
@@ -7,31 +7,38 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, description, site_url, canonical_url)
 VALUES (
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7,
+    $8,
+    $9
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, description, site_url, channel_title, language, image_url, generator, canonical_url, redirect_url, redirect_count, headers, auth_type, auth_secret, fetch_status, last_attempted_at, last_posts_inserted, last_posts_updated, last_posts_skipped, orphaned_at, archived_at, retention_days, retention_max_posts, keep_forever
 `
 
 type CreateFeedParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
-	Url       string
-	UserID    uuid.UUID
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	Url          string
+	UserID       uuid.UUID
+	Description  sql.NullString
+	SiteUrl      sql.NullString
+	CanonicalUrl string
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -42,6 +49,9 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.Name,
 		arg.Url,
 		arg.UserID,
+		arg.Description,
+		arg.SiteUrl,
+		arg.CanonicalUrl,
 	)
 	var i Feed
 	err := row.Scan(
@@ -52,6 +62,28 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Description,
+		&i.SiteUrl,
+		&i.ChannelTitle,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.CanonicalUrl,
+		&i.RedirectUrl,
+		&i.RedirectCount,
+		&i.Headers,
+		&i.AuthType,
+		&i.AuthSecret,
+		&i.FetchStatus,
+		&i.LastAttemptedAt,
+		&i.LastPostsInserted,
+		&i.LastPostsUpdated,
+		&i.LastPostsSkipped,
+		&i.OrphanedAt,
+		&i.ArchivedAt,
+		&i.RetentionDays,
+		&i.RetentionMaxPosts,
+		&i.KeepForever,
 	)
 	return i, err
 }
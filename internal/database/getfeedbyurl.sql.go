@@ -11,11 +11,11 @@ import (
 
 const getFeedByUrl = `-- name: GetFeedByUrl :one
 
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, description, site_url, channel_title, language, image_url, generator, canonical_url, redirect_url, redirect_count, headers, auth_type, auth_secret, fetch_status, last_attempted_at, last_posts_inserted, last_posts_updated, last_posts_skipped, orphaned_at, archived_at, retention_days, retention_max_posts, keep_forever FROM feeds WHERE canonical_url = $1
 `
 
-func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getFeedByUrl, url)
+func (q *Queries) GetFeedByUrl(ctx context.Context, canonicalUrl string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByUrl, canonicalUrl)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -25,6 +25,28 @@ func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Description,
+		&i.SiteUrl,
+		&i.ChannelTitle,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.CanonicalUrl,
+		&i.RedirectUrl,
+		&i.RedirectCount,
+		&i.Headers,
+		&i.AuthType,
+		&i.AuthSecret,
+		&i.FetchStatus,
+		&i.LastAttemptedAt,
+		&i.LastPostsInserted,
+		&i.LastPostsUpdated,
+		&i.LastPostsSkipped,
+		&i.OrphanedAt,
+		&i.ArchivedAt,
+		&i.RetentionDays,
+		&i.RetentionMaxPosts,
+		&i.KeepForever,
 	)
 	return i, err
 }
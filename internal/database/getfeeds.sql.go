@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 )
 
 const getFeeds = `-- name: GetFeeds :many
@@ -14,15 +15,27 @@ const getFeeds = `-- name: GetFeeds :many
 SELECT 
     f.name  AS feed_name,
     f.url   AS feed_url,
-    u.name  AS user_name
+    u.name  AS user_name,
+    f.channel_title,
+    f.site_url,
+    f.description,
+    f.language,
+    f.auth_type,
+    f.fetch_status
 FROM feeds AS f
 JOIN users AS u ON f.user_id = u.id
 `
 
 type GetFeedsRow struct {
-	FeedName string
-	FeedUrl  string
-	UserName string
+	FeedName     string
+	FeedUrl      string
+	UserName     string
+	ChannelTitle sql.NullString
+	SiteUrl      sql.NullString
+	Description  sql.NullString
+	Language     sql.NullString
+	AuthType     sql.NullString
+	FetchStatus  sql.NullString
 }
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
@@ -34,7 +47,17 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 	var items []GetFeedsRow
 	for rows.Next() {
 		var i GetFeedsRow
-		if err := rows.Scan(&i.FeedName, &i.FeedUrl, &i.UserName); err != nil {
+		if err := rows.Scan(
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.UserName,
+			&i.ChannelTitle,
+			&i.SiteUrl,
+			&i.Description,
+			&i.Language,
+			&i.AuthType,
+			&i.FetchStatus,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -14,34 +14,53 @@ import (
 
 const getPostsforUser = `-- name: GetPostsforUser :many
 
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id
+SELECT DISTINCT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.author, posts.content, posts.content_hash, posts.canonical_url, posts.resolved_url
 FROM users 
 LEFT JOIN feed_follow ON users.id = feed_follow.user_id
-LEFT JOIN feeds ON feed_follow.feed_id = feeds.id
-LEFT JOIN posts ON feeds.id = posts.feed_id
+LEFT JOIN feed_posts ON feed_follow.feed_id = feed_posts.feed_id
+LEFT JOIN posts ON feed_posts.post_id = posts.id
 WHERE users.name = $1
+    AND ($2::text IS NULL OR posts.author = $2)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM post_categories
+        INNER JOIN categories ON post_categories.category_id = categories.id
+        WHERE post_categories.post_id = posts.id
+            AND categories.name = $3
+    ))
 ORDER BY published_at DESC
-LIMIT $2
+LIMIT $4
 `
 
 type GetPostsforUserParams struct {
-	Name  string
-	Limit int32
+	Name     string
+	Author   sql.NullString
+	Category sql.NullString
+	Limit    int32
 }
 
 type GetPostsforUserRow struct {
-	ID          uuid.NullUUID
-	CreatedAt   sql.NullTime
-	UpdatedAt   sql.NullTime
-	Title       sql.NullString
-	Url         sql.NullString
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.NullUUID
+	ID           uuid.NullUUID
+	CreatedAt    sql.NullTime
+	UpdatedAt    sql.NullTime
+	Title        sql.NullString
+	Url          sql.NullString
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	Author       sql.NullString
+	Content      sql.NullString
+	ContentHash  sql.NullString
+	CanonicalUrl sql.NullString
+	ResolvedUrl  sql.NullString
 }
 
 func (q *Queries) GetPostsforUser(ctx context.Context, arg GetPostsforUserParams) ([]GetPostsforUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsforUser, arg.Name, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsforUser,
+		arg.Name,
+		arg.Author,
+		arg.Category,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +76,11 @@ func (q *Queries) GetPostsforUser(ctx context.Context, arg GetPostsforUserParams
 			&i.Url,
 			&i.Description,
 			&i.PublishedAt,
-			&i.FeedID,
+			&i.Author,
+			&i.Content,
+			&i.ContentHash,
+			&i.CanonicalUrl,
+			&i.ResolvedUrl,
 		); err != nil {
 			return nil, err
 		}
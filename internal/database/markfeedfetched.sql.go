@@ -15,11 +15,26 @@ const markFeedFetched = `-- name: MarkFeedFetched :exec
 
 UPDATE feeds
 SET last_fetched_at = NOW(),
-    updated_at = NOW()
+    updated_at = NOW(),
+    last_posts_inserted = $2,
+    last_posts_updated = $3,
+    last_posts_skipped = $4
 WHERE id = $1
 `
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
+type MarkFeedFetchedParams struct {
+	ID                uuid.UUID
+	LastPostsInserted int32
+	LastPostsUpdated  int32
+	LastPostsSkipped  int32
+}
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetched,
+		arg.ID,
+		arg.LastPostsInserted,
+		arg.LastPostsUpdated,
+		arg.LastPostsSkipped,
+	)
 	return err
 }
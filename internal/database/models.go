@@ -6,19 +6,61 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Category struct {
+	ID   uuid.UUID
+	Name string
+}
+
 type Feed struct {
+	ID                uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Name              string
+	Url               string
+	UserID            uuid.UUID
+	LastFetchedAt     sql.NullTime
+	Description       sql.NullString
+	SiteUrl           sql.NullString
+	ChannelTitle      sql.NullString
+	Language          sql.NullString
+	ImageUrl          sql.NullString
+	Generator         sql.NullString
+	CanonicalUrl      string
+	RedirectUrl       sql.NullString
+	RedirectCount     int32
+	Headers           json.RawMessage
+	AuthType          sql.NullString
+	AuthSecret        []byte
+	FetchStatus       sql.NullString
+	LastAttemptedAt   sql.NullTime
+	LastPostsInserted int32
+	LastPostsUpdated  int32
+	LastPostsSkipped  int32
+	OrphanedAt        sql.NullTime
+	ArchivedAt        sql.NullTime
+	RetentionDays     sql.NullInt32
+	RetentionMaxPosts sql.NullInt32
+	KeepForever       bool
+}
+
+type FeedFetch struct {
 	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	FeedID        uuid.UUID
+	StartedAt     time.Time
+	FinishedAt    time.Time
+	DurationMs    int64
+	StatusCode    sql.NullInt32
+	Bytes         int64
+	ItemsSeen     int32
+	PostsInserted int32
+	PostsUpdated  int32
+	Error         sql.NullString
 }
 
 type FeedFollow struct {
@@ -29,15 +71,36 @@ type FeedFollow struct {
 	FeedID    uuid.UUID
 }
 
+type FeedPost struct {
+	FeedID    uuid.UUID
+	PostID    uuid.UUID
+	Guid      sql.NullString
+	CreatedAt time.Time
+}
+
+type HostCooldown struct {
+	Host  string
+	Until time.Time
+}
+
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt time.Time
-	FeedID      uuid.UUID
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  time.Time
+	Author       sql.NullString
+	Content      sql.NullString
+	ContentHash  sql.NullString
+	CanonicalUrl string
+	ResolvedUrl  sql.NullString
+}
+
+type PostCategory struct {
+	PostID     uuid.UUID
+	CategoryID uuid.UUID
 }
 
 type User struct {
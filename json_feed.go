@@ -0,0 +1,65 @@
+package main
+
+import (
+	"cmp"
+	"strings"
+)
+
+// jsonFeedVersionPrefix starts the version of every JSON Feed document.
+const jsonFeedVersionPrefix = "https://jsonfeed.org/version/"
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Icon        string         `json:"icon"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	ExternalURL   string `json:"external_url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+	// Author is the JSON Feed 1.0 field, replaced by Authors in 1.1.
+	Author  JSONFeedAuthor   `json:"author"`
+	Authors []JSONFeedAuthor `json:"authors"`
+	Tags    []string         `json:"tags"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// RSS converts the feed to the RSS structure the rest of gator works with.
+func (f *JSONFeed) RSS() *RSSFeed {
+	feed := &RSSFeed{}
+	feed.Channel.Title = strings.TrimSpace(f.Title)
+	feed.Channel.Link = strings.TrimSpace(f.HomePageURL)
+	feed.Channel.Description = strings.TrimSpace(f.Description)
+	feed.Channel.Image.URL = strings.TrimSpace(f.Icon)
+
+	for _, jsonItem := range f.Items {
+		item := RSSItem{
+			Title:       jsonItem.Title,
+			Link:        cmp.Or(jsonItem.URL, jsonItem.ExternalURL),
+			Description: jsonItem.Summary,
+			PubDate:     rssDate(cmp.Or(jsonItem.DatePublished, jsonItem.DateModified)),
+			GUID:        jsonItem.ID,
+			Author:      jsonItem.Author.Name,
+			Categories:  jsonItem.Tags,
+			Content:     cmp.Or(jsonItem.ContentHTML, jsonItem.ContentText),
+		}
+		if len(jsonItem.Authors) > 0 {
+			item.Author = jsonItem.Authors[0].Name
+		}
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
+	return feed
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/LouisRemes-95/gator/internal/canonical"
+	"github.com/google/uuid"
+)
+
+const (
+	maxPostLinkRedirects = 10
+	postLinkTimeout      = 10 * time.Second
+	// postLinkRetryAfter is how long a link that failed to resolve is left
+	// alone, so a broken link does not cost a request every round.
+	postLinkRetryAfter = 24 * time.Hour
+)
+
+// linkFailures remembers the post links that recently failed to resolve.
+type linkFailures struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+func newLinkFailures() *linkFailures {
+	return &linkFailures{
+		until: make(map[string]time.Time),
+	}
+}
+
+func (l *linkFailures) recent(link string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until, ok := l.until[link]
+	if ok && time.Now().After(until) {
+		delete(l.until, link)
+		return false
+	}
+	return ok
+}
+
+func (l *linkFailures) add(link string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.until[link] = time.Now().Add(postLinkRetryAfter)
+}
+
+// resolvePostLinks maps the links of the items that would create posts to the
+// URL they finally land on, with tracking parameters stripped. Items matching
+// a stored post, or dropped by the feed's retention, are not resolved again.
+// Links that fail to resolve are left out so the original link is used
+// instead, and are not retried for a while.
+func resolvePostLinks(s *state, log *slog.Logger, feedID uuid.UUID, rssItems []RSSItem, retention postRetention) (map[string]string, error) {
+	items, err := matchItems(s.db, feedID, rssItems, nil, retention)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]string)
+	for _, item := range items {
+		link := item.url
+		if item.postID != uuid.Nil || link == "" {
+			continue
+		}
+		if _, ok := resolved[link]; ok {
+			continue
+		}
+		if s.fetcher.failedLinks.recent(link) {
+			continue
+		}
+
+		known, err := s.db.GetResolvedUrl(context.Background(), link)
+		if err == nil {
+			resolved[link] = known.String
+			continue
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Warn("failed to look up resolved link", "link", link, "error", err)
+			continue
+		}
+
+		target, err := s.fetcher.resolvePostLink(context.Background(), link)
+		if err != nil {
+			s.fetcher.failedLinks.add(link)
+			log.Warn("failed to resolve link", "link", link, "error", err)
+			continue
+		}
+		resolved[link] = target
+	}
+	return resolved, nil
+}
+
+// resolvePostLink follows the redirect chain of link, such as FeedBurner or
+// click-tracking wrappers, and returns the final URL without tracking
+// parameters. Every hop must be allowed by its host's robots.txt.
+func (f *feedFetcher) resolvePostLink(ctx context.Context, link string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, postLinkTimeout)
+	defer cancel()
+
+	client := &http.Client{
+		Transport: f.client.Transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxPostLinkRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxPostLinkRedirects)
+			}
+			return f.checkRobots(req.Context(), req.URL.String())
+		},
+	}
+
+	err := f.checkRobots(ctx, link)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := f.requestPostLink(ctx, client, http.MethodHead, link)
+	if err == nil && (response.StatusCode == http.StatusMethodNotAllowed || response.StatusCode == http.StatusNotImplemented) {
+		response, err = f.requestPostLink(ctx, client, http.MethodGet, link)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return canonical.StripTracking(response.Request.URL.String()), nil
+}
+
+// checkRobots fails with errRobotsDisallowed when robots.txt disallows rawURL.
+func (f *feedFetcher) checkRobots(ctx context.Context, rawURL string) error {
+	allowed, err := f.robotsAllowed(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s", errRobotsDisallowed, rawURL)
+	}
+	return nil
+}
+
+func (f *feedFetcher) requestPostLink(ctx context.Context, client *http.Client, method, link string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	request.Header.Set("User-Agent", f.userAgent)
+
+	response, err := f.do(client, request)
+	if err != nil {
+		return nil, err
+	}
+	// Only the final URL matters, the body is never read.
+	response.Body.Close()
+	return response, nil
+}
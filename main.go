@@ -2,8 +2,9 @@ package main
 
 import (
 	"database/sql"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
 	"github.com/LouisRemes-95/gator/internal/config"
@@ -12,40 +13,56 @@ import (
 )
 
 func main() {
+	flags := flag.NewFlagSet("gator", flag.ContinueOnError)
+	logLevel := flags.String("log-level", "info", "minimum level logged: debug, info, warn or error")
+	logFormat := flags.String("log-format", "text", "log format: text or json")
+	err := flags.Parse(os.Args[1:])
+	if err != nil {
+		os.Exit(2)
+	}
+
+	logger, err := newLogger(os.Stderr, *logLevel, *logFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
 	cfg, err := config.Read()
 	if err != nil {
-		log.Fatal("Failed to load config:", err)
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
 	}
 
 	db, err := sql.Open("postgres", cfg.DbUrl)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		slog.Error("failed to open the database", "error", err)
 		os.Exit(1)
 	}
 	dbQueries := database.New(db)
 
 	programState := &state{
-		db:  dbQueries,
-		cfg: &cfg,
+		db:      dbQueries,
+		sqlDB:   db,
+		cfg:     &cfg,
+		fetcher: newFeedFetcher(&cfg),
 	}
 
 	programCommands := registeredCommands()
 
-	if len(os.Args) < 2 {
-		fmt.Println("Not enough input arguments")
+	if flags.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "usage: gator [--log-level level] [--log-format text|json] <command> [args...]")
 		os.Exit(1)
 	}
 
 	requestedCommand := command{
-		Name: os.Args[1],
-		Args: os.Args[2:],
+		Name: flags.Arg(0),
+		Args: flags.Args()[1:],
 	}
 
 	err = programCommands.run(programState, requestedCommand)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		slog.Error("command failed", "command", requestedCommand.Name, "error", err)
 		os.Exit(1)
 	}
-
-	os.Exit(0)
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics served on /metrics while agg runs with an HTTP address.
+var (
+	feedFetches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gator_feed_fetches_total",
+		Help: "Feed fetches by outcome, one of ok, error or robots_disallowed.",
+	}, []string{"outcome"})
+	feedFetchDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "gator_feed_fetch_duration_seconds",
+		Help:    "Time to download, decompress and decode a feed, from when its host allows the request.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 11),
+	})
+	feedFetchBytes = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gator_feed_fetch_bytes_total",
+		Help: "Bytes downloaded fetching feeds, as sent on the wire.",
+	})
+	postsInserted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gator_posts_inserted_total",
+		Help: "Posts stored for the first time.",
+	})
+	postsUpdated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gator_posts_updated_total",
+		Help: "Stored posts whose content changed.",
+	})
+	scrapeRoundDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "gator_scrape_round_duration_seconds",
+		Help:    "Time taken by one aggregation round.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+	schedulerLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "gator_scheduler_lag_seconds",
+		Help: "Time since the feed waiting longest was last attempted.",
+	})
+	dbErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gator_db_errors_total",
+		Help: "Failed database operations while aggregating, by operation.",
+	}, []string{"operation"})
+)
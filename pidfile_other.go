@@ -0,0 +1,38 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// pidLock is a pid file created exclusively. Without flock a crashed agg
+// leaves it behind and it has to be removed by hand.
+type pidLock struct {
+	path string
+}
+
+func lockPIDFile(path string) (*pidLock, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("agg is already running, or remove the stale pid file %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the pid file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to write the pid file: %w", err)
+	}
+	return &pidLock{path: path}, nil
+}
+
+func (l *pidLock) release() {
+	os.Remove(l.path)
+}
@@ -0,0 +1,51 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// pidLock is a pid file held with an exclusive flock, which the kernel drops
+// if agg dies without cleaning up.
+type pidLock struct {
+	file *os.File
+}
+
+func lockPIDFile(path string) (*pidLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the pid file: %w", err)
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		data, _ := os.ReadFile(path)
+		file.Close()
+		return nil, fmt.Errorf("agg is already running with pid %s (%s)", strings.TrimSpace(string(data)), path)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock the pid file: %w", err)
+	}
+
+	err = file.Truncate(0)
+	if err == nil {
+		_, err = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write the pid file: %w", err)
+	}
+	return &pidLock{file: file}, nil
+}
+
+func (l *pidLock) release() {
+	os.Remove(l.file.Name())
+	l.file.Close()
+}
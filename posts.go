@@ -0,0 +1,361 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LouisRemes-95/gator/internal/canonical"
+	"github.com/LouisRemes-95/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// ingestFeed stores the metadata and items of a fetched feed and marks it
+// fetched with the outcome, in one transaction so that a failing item leaves
+// the feed as it was.
+func ingestFeed(s *state, feedID uuid.UUID, rssFeed *RSSFeed, resolvedLinks map[string]string, retention postRetention) (database.MarkFeedFetchedParams, error) {
+	tx, err := s.sqlDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return database.MarkFeedFetchedParams{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	err = qtx.UpdateFeedMetadata(context.Background(), feedMetadataParams(feedID, rssFeed))
+	if err != nil {
+		return database.MarkFeedFetchedParams{}, fmt.Errorf("failed to update the feed metadata: %w", err)
+	}
+
+	counts, err := upsertPosts(qtx, feedID, rssFeed.Channel.Item, resolvedLinks, retention)
+	if err != nil {
+		return counts, err
+	}
+
+	err = qtx.MarkFeedFetched(context.Background(), counts)
+	if err != nil {
+		return counts, fmt.Errorf("failed to mark the feed fetched: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return counts, fmt.Errorf("failed to commit the feed: %w", err)
+	}
+	return counts, nil
+}
+
+// feedItem is an item with the values used to store it.
+type feedItem struct {
+	RSSItem
+	postID       uuid.UUID
+	url          string
+	canonicalURL string
+	resolvedURL  string
+	hash         string
+	publishedAt  time.Time
+}
+
+// upsertPosts stores the items as posts of the feed with a fixed number of
+// bulk queries. Items are matched to existing posts by GUID within the feed,
+// then by canonical URL, then by content hash, so an article syndicated by
+// several feeds is stored once and linked to each. resolvedLinks maps item
+// links to the URL they land on, preferred over the link for matching.
+// Items without a valid pubDate, repeated within the feed, past the feed's
+// retention or unchanged are skipped.
+func upsertPosts(q *database.Queries, feedID uuid.UUID, rssItems []RSSItem, resolvedLinks map[string]string, retention postRetention) (database.MarkFeedFetchedParams, error) {
+	counts := database.MarkFeedFetchedParams{
+		ID: feedID,
+	}
+
+	items, err := matchItems(q, feedID, rssItems, resolvedLinks, retention)
+	if err != nil {
+		return counts, err
+	}
+	counts.LastPostsSkipped = int32(len(rssItems) - len(items))
+	if len(items) == 0 {
+		return counts, nil
+	}
+
+	var toCreate, toUpdate []feedItem
+	seen := make(map[uuid.UUID]bool)
+	kept := items[:0]
+	for _, item := range items {
+		if item.postID == uuid.Nil {
+			item.postID = uuid.New()
+			toCreate = append(toCreate, item)
+		} else {
+			// Several items of the feed may match the same post.
+			if seen[item.postID] {
+				counts.LastPostsSkipped++
+				continue
+			}
+			toUpdate = append(toUpdate, item)
+		}
+		seen[item.postID] = true
+		kept = append(kept, item)
+	}
+	items = kept
+
+	// Feeds are stored concurrently and may share posts, rows are written in
+	// key order so that two transactions never wait on each other.
+	slices.SortFunc(toCreate, func(a, b feedItem) int {
+		return strings.Compare(a.canonicalURL, b.canonicalURL)
+	})
+	slices.SortFunc(toUpdate, func(a, b feedItem) int {
+		return strings.Compare(a.postID.String(), b.postID.String())
+	})
+
+	var created database.CreatePostsParams
+	for _, item := range toCreate {
+		created.Ids = append(created.Ids, item.postID)
+		created.Titles = append(created.Titles, item.Title)
+		created.Urls = append(created.Urls, item.url)
+		created.Descriptions = append(created.Descriptions, item.Description)
+		created.PublishedAts = append(created.PublishedAts, item.publishedAt)
+		created.Authors = append(created.Authors, item.AuthorName())
+		created.Contents = append(created.Contents, item.Content)
+		created.ContentHashes = append(created.ContentHashes, item.hash)
+		created.CanonicalUrls = append(created.CanonicalUrls, item.canonicalURL)
+		created.ResolvedUrls = append(created.ResolvedUrls, item.resolvedURL)
+	}
+
+	var updated database.UpdatePostsParams
+	for _, item := range toUpdate {
+		updated.Ids = append(updated.Ids, item.postID)
+		updated.Titles = append(updated.Titles, item.Title)
+		updated.Descriptions = append(updated.Descriptions, item.Description)
+		updated.PublishedAts = append(updated.PublishedAts, item.publishedAt)
+		updated.Authors = append(updated.Authors, item.AuthorName())
+		updated.Contents = append(updated.Contents, item.Content)
+		updated.ContentHashes = append(updated.ContentHashes, item.hash)
+	}
+
+	if len(created.Ids) > 0 {
+		rows, err := q.CreatePosts(context.Background(), created)
+		if err != nil {
+			return counts, fmt.Errorf("failed to create the posts: %w", err)
+		}
+
+		// A post created by another feed meanwhile keeps its own id.
+		stored := make(map[string]uuid.UUID, len(rows))
+		for _, row := range rows {
+			stored[row.CanonicalUrl] = row.ID
+		}
+		for i := range items {
+			id, ok := stored[items[i].canonicalURL]
+			if !ok {
+				continue
+			}
+			if id == items[i].postID {
+				counts.LastPostsInserted++
+			} else {
+				counts.LastPostsSkipped++
+				items[i].postID = id
+			}
+		}
+	}
+
+	if len(updated.Ids) > 0 {
+		rows, err := q.UpdatePosts(context.Background(), updated)
+		if err != nil {
+			return counts, fmt.Errorf("failed to update the posts: %w", err)
+		}
+		counts.LastPostsUpdated = int32(rows)
+		counts.LastPostsSkipped += int32(len(updated.Ids)) - int32(rows)
+	}
+
+	links := database.CreateFeedPostsParams{
+		FeedID: feedID,
+	}
+	var categories database.CreatePostCategoriesParams
+	linked := make(map[uuid.UUID]bool)
+	for _, item := range items {
+		if linked[item.postID] {
+			continue
+		}
+		linked[item.postID] = true
+		links.PostIds = append(links.PostIds, item.postID)
+		links.Guids = append(links.Guids, item.GUID)
+
+		for _, name := range postCategories(item.Categories) {
+			categories.PostIds = append(categories.PostIds, item.postID)
+			categories.Names = append(categories.Names, name)
+		}
+	}
+
+	err = q.CreateFeedPosts(context.Background(), links)
+	if err != nil {
+		return counts, fmt.Errorf("failed to link the posts to the feed: %w", err)
+	}
+
+	err = addPostCategories(q, categories)
+	if err != nil {
+		return counts, fmt.Errorf("failed to add the post categories: %w", err)
+	}
+	return counts, nil
+}
+
+// matchItems prepares the items kept by the feed's retention and sets the id
+// of the stored post each one matches, leaving it nil for new posts.
+func matchItems(q *database.Queries, feedID uuid.UUID, rssItems []RSSItem, resolvedLinks map[string]string, retention postRetention) ([]feedItem, error) {
+	items := retainItems(prepareItems(feedID, rssItems, resolvedLinks), retention)
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	lookup := database.FindPostIDsParams{
+		FeedID: feedID,
+	}
+	for _, item := range items {
+		lookup.Guids = append(lookup.Guids, item.GUID)
+		lookup.CanonicalUrls = append(lookup.CanonicalUrls, item.canonicalURL)
+		lookup.ContentHashes = append(lookup.ContentHashes, item.hash)
+	}
+	matches, err := q.FindPostIDs(context.Background(), lookup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the posts: %w", err)
+	}
+	for _, match := range matches {
+		items[match.Idx-1].postID = match.PostID
+	}
+	return items, nil
+}
+
+// prepareItems computes the values stored for each item, dropping items
+// without a valid pubDate and items sharing a GUID, canonical URL or content
+// hash with an earlier one.
+func prepareItems(feedID uuid.UUID, rssItems []RSSItem, resolvedLinks map[string]string) []feedItem {
+	var items []feedItem
+	seen := make(map[string]bool)
+	for _, rssItem := range rssItems {
+		publishTime, err := time.Parse(time.RFC1123Z, rssItem.PubDate)
+		if err != nil {
+			slog.Warn("skipping post with an invalid pubDate", "feed_id", feedID, "link", rssItem.Link, "error", err)
+			continue
+		}
+
+		item := feedItem{
+			RSSItem:     rssItem,
+			url:         strings.TrimSpace(rssItem.Link),
+			publishedAt: publishTime,
+			hash:        contentHash(rssItem),
+		}
+		item.resolvedURL = resolvedLinks[item.url]
+
+		targetURL := item.url
+		if item.resolvedURL != "" {
+			targetURL = item.resolvedURL
+		}
+		item.canonicalURL, err = canonical.URL(targetURL)
+		if err != nil {
+			item.canonicalURL = targetURL
+		}
+
+		var keys []string
+		if item.GUID != "" {
+			keys = append(keys, "guid "+item.GUID)
+		}
+		keys = append(keys, "url "+item.canonicalURL)
+		if item.hash != "" {
+			keys = append(keys, "hash "+item.hash)
+		}
+
+		duplicate := false
+		for _, key := range keys {
+			duplicate = duplicate || seen[key]
+			seen[key] = true
+		}
+		if !duplicate {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// contentHash fingerprints an item by its title and body. Items without a
+// body get no hash, as a title alone such as "Weekly links" does not tell
+// articles apart. It must match the hash computed in
+// sql/schema/009_feed_posts.sql.
+func contentHash(item RSSItem) string {
+	body := item.Content
+	if body == "" {
+		body = item.Description
+	}
+
+	title := strings.ToLower(strings.Trim(item.Title, " \t\r\n"))
+	body = strings.Trim(body, " \t\r\n")
+	if body == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(title + "\n" + body))
+	return hex.EncodeToString(sum[:])
+}
+
+// postCategories returns the distinct, trimmed category names of an item.
+func postCategories(names []string) []string {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		categories = append(categories, name)
+	}
+	return categories
+}
+
+// addPostCategories creates the missing categories, then links the posts to
+// them. Both are written in key order, like the posts, as other feeds may be
+// adding the same categories at the same time.
+func addPostCategories(q *database.Queries, categories database.CreatePostCategoriesParams) error {
+	if len(categories.PostIds) == 0 {
+		return nil
+	}
+
+	type postCategory struct {
+		postID uuid.UUID
+		name   string
+	}
+	pairs := make([]postCategory, len(categories.PostIds))
+	for i := range categories.PostIds {
+		pairs[i] = postCategory{categories.PostIds[i], categories.Names[i]}
+	}
+	slices.SortFunc(pairs, func(a, b postCategory) int {
+		return cmp.Or(
+			strings.Compare(a.postID.String(), b.postID.String()),
+			strings.Compare(a.name, b.name),
+		)
+	})
+	var sorted database.CreatePostCategoriesParams
+	for _, pair := range pairs {
+		sorted.PostIds = append(sorted.PostIds, pair.postID)
+		sorted.Names = append(sorted.Names, pair.name)
+	}
+
+	names := slices.Clone(categories.Names)
+	slices.Sort(names)
+	var created database.CreateCategoriesParams
+	for _, name := range slices.Compact(names) {
+		created.Ids = append(created.Ids, uuid.New())
+		created.Names = append(created.Names, name)
+	}
+
+	err := q.CreateCategories(context.Background(), created)
+	if err != nil {
+		return fmt.Errorf("failed to create the categories: %w", err)
+	}
+
+	err = q.CreatePostCategories(context.Background(), sorted)
+	if err != nil {
+		return fmt.Errorf("failed to link the categories: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/LouisRemes-95/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// benchmarkDBEnv names the database the benchmarks write to. Every iteration
+// runs in a transaction that is rolled back, so a migrated development
+// database can be used.
+const benchmarkDBEnv = "GATOR_TEST_DB_URL"
+
+const benchmarkFeedItems = 200
+
+// BenchmarkUpsertPosts compares storing a feed with the bulk queries to the
+// per-item loop they replaced, one statement per item. The per-item baseline
+// only writes the posts while the bulk path also links them to the feed and
+// their categories, so the speed-up it shows is a lower bound.
+func BenchmarkUpsertPosts(b *testing.B) {
+	dsn := os.Getenv(benchmarkDBEnv)
+	if dsn == "" {
+		b.Skipf("set %s to a migrated postgres database to run", benchmarkDBEnv)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+	err = db.Ping()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("bulk", func(b *testing.B) {
+		benchmarkUpsertPosts(b, db, func(tx *sql.Tx, q *database.Queries, feedID uuid.UUID, items []RSSItem) error {
+			_, err := upsertPosts(q, feedID, items, nil, postRetention{})
+			return err
+		})
+	})
+	b.Run("per-item", func(b *testing.B) {
+		benchmarkUpsertPosts(b, db, upsertPostsPerItem)
+	})
+}
+
+// upsertPostPerItem is the single statement the per-item loop ran for each
+// item: create the post, or update it when its content or date changed.
+const upsertPostPerItem = `
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, author, content, content_hash, canonical_url, resolved_url)
+VALUES ($1, NOW(), NOW(), $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, NULLIF($10, ''))
+ON CONFLICT (canonical_url)
+DO UPDATE SET updated_at = NOW(),
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    published_at = EXCLUDED.published_at,
+    author = EXCLUDED.author,
+    content = EXCLUDED.content,
+    content_hash = EXCLUDED.content_hash
+WHERE posts.content_hash IS DISTINCT FROM EXCLUDED.content_hash
+    OR posts.published_at < EXCLUDED.published_at`
+
+func upsertPostsPerItem(tx *sql.Tx, q *database.Queries, feedID uuid.UUID, rssItems []RSSItem) error {
+	for _, item := range prepareItems(feedID, rssItems, nil) {
+		_, err := tx.ExecContext(context.Background(), upsertPostPerItem,
+			uuid.New(),
+			item.Title,
+			item.url,
+			item.Description,
+			item.publishedAt,
+			item.AuthorName(),
+			item.Content,
+			item.hash,
+			item.canonicalURL,
+			item.resolvedURL,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// benchmarkUpsertPosts times store on a feed of new items, then on the same
+// items again as a refetch, inside a transaction rolled back afterwards.
+func benchmarkUpsertPosts(b *testing.B, db *sql.DB, store func(tx *sql.Tx, q *database.Queries, feedID uuid.UUID, items []RSSItem) error) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tx, err := db.BeginTx(context.Background(), nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		q := database.New(db).WithTx(tx)
+		feedID := createBenchmarkFeed(b, q)
+		items := benchmarkItems(feedID)
+		b.StartTimer()
+
+		err = store(tx, q, feedID, items)
+		if err == nil {
+			err = store(tx, q, feedID, items)
+		}
+
+		b.StopTimer()
+		tx.Rollback()
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+	}
+}
+
+func createBenchmarkFeed(b *testing.B, q *database.Queries) uuid.UUID {
+	b.Helper()
+	user, err := q.CreateUser(context.Background(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      "benchmark-" + uuid.NewString(),
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	feedURL := "https://bench.example.com/" + uuid.NewString() + "/rss"
+	feed, err := q.CreateFeed(context.Background(), database.CreateFeedParams{
+		ID:           uuid.New(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		Name:         "benchmark",
+		Url:          feedURL,
+		UserID:       user.ID,
+		CanonicalUrl: feedURL,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return feed.ID
+}
+
+func benchmarkItems(feedID uuid.UUID) []RSSItem {
+	items := make([]RSSItem, benchmarkFeedItems)
+	published := time.Now().Add(-time.Hour)
+	for i := range items {
+		link := fmt.Sprintf("https://bench.example.com/%s/posts/%d", feedID, i)
+		items[i] = RSSItem{
+			Title:       fmt.Sprintf("Post %d", i),
+			Link:        link,
+			Description: fmt.Sprintf("Body of post %d", i),
+			PubDate:     published.Add(time.Duration(i) * time.Second).Format(time.RFC1123Z),
+			GUID:        link,
+			Categories:  []string{"benchmark", fmt.Sprintf("category-%d", i%10)},
+		}
+	}
+	return items
+}
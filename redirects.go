@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/LouisRemes-95/gator/internal/canonical"
+	"github.com/LouisRemes-95/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// permanentRedirectThreshold is how many fetches in a row must be permanently
+// redirected to the same URL before the stored feed url is updated.
+const permanentRedirectThreshold = 3
+
+// trackFeedRedirect records a permanent redirect seen while fetching the feed
+// and, once it has been seen consistently, moves the feed to the new URL or
+// merges it into the feed already stored for that URL. It returns the id of
+// the feed the fetched posts belong to.
+func trackFeedRedirect(s *state, feed database.Feed, target string) (uuid.UUID, error) {
+	if target == "" {
+		return feed.ID, s.db.ClearFeedRedirect(context.Background(), feed.ID)
+	}
+
+	count, err := s.db.RecordFeedRedirect(context.Background(), database.RecordFeedRedirectParams{
+		ID:          feed.ID,
+		RedirectUrl: nullString(target),
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to record the redirect: %w", err)
+	}
+	if count < permanentRedirectThreshold {
+		return feed.ID, nil
+	}
+
+	canonicalURL, err := canonical.URL(target)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to canonicalise url: %w", err)
+	}
+
+	existing, err := s.db.GetFeedByUrl(context.Background(), canonicalURL)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && existing.ID == feed.ID) {
+		err = s.db.UpdateFeedUrl(context.Background(), database.UpdateFeedUrlParams{
+			ID:           feed.ID,
+			Url:          target,
+			CanonicalUrl: canonicalURL,
+		})
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("failed to update the feed url: %w", err)
+		}
+		feedLogger(feed.ID, feed.Url).Info("feed moved permanently, url updated", "name", feed.Name, "new_url", target)
+		return feed.ID, nil
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get feed by url: %w", err)
+	}
+
+	// Merging would silently drop what the moved feed needs to be fetched,
+	// so it keeps being fetched through the redirect until the user acts.
+	missing, err := missingFetchSettings(feed, existing)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if missing != "" {
+		feedLogger(feed.ID, feed.Url).Warn("feed moved permanently to a known feed, not merging into a feed without its settings",
+			"missing", missing,
+			"name", feed.Name,
+			"new_url", target,
+			"target_id", existing.ID,
+		)
+		return feed.ID, nil
+	}
+
+	err = mergeFeed(s, feed.ID, existing.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to merge into feed %s: %w", existing.Url, err)
+	}
+	feedLogger(feed.ID, feed.Url).Info("feed moved permanently, merged into an existing feed",
+		"name", feed.Name,
+		"new_url", target,
+		"merged_into", existing.ID,
+	)
+	return existing.ID, nil
+}
+
+// missingFetchSettings names the credentials or headers of from that to does
+// not have, or returns "" when merging from into to loses nothing.
+func missingFetchSettings(from, to database.Feed) (string, error) {
+	var missing []string
+	if from.AuthType.Valid && !to.AuthType.Valid {
+		missing = append(missing, "credentials")
+	}
+
+	fromHeaders, err := feedHeaders(from)
+	if err != nil {
+		return "", err
+	}
+	toHeaders, err := feedHeaders(to)
+	if err != nil {
+		return "", err
+	}
+	for name, value := range fromHeaders {
+		if toHeaders[name] != value {
+			missing = append(missing, "headers")
+			break
+		}
+	}
+	return strings.Join(missing, " and "), nil
+}
+
+// mergeFeed moves the follows, posts and fetch history of one feed to another
+// and deletes the first one.
+func mergeFeed(s *state, fromID, toID uuid.UUID) error {
+	tx, err := s.sqlDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	err = qtx.MoveFeedFollows(context.Background(), database.MoveFeedFollowsParams{
+		ToFeedID:   toID,
+		FromFeedID: fromID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move the follows: %w", err)
+	}
+
+	err = qtx.MoveFeedPosts(context.Background(), database.MoveFeedPostsParams{
+		ToFeedID:   toID,
+		FromFeedID: fromID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move the posts: %w", err)
+	}
+
+	err = qtx.MoveFeedFetches(context.Background(), database.MoveFeedFetchesParams{
+		ToFeedID:   toID,
+		FromFeedID: fromID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move the fetch history: %w", err)
+	}
+
+	err = qtx.DeleteFeed(context.Background(), fromID)
+	if err != nil {
+		return fmt.Errorf("failed to delete the feed: %w", err)
+	}
+
+	return tx.Commit()
+}
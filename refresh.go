@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/LouisRemes-95/gator/internal/database"
+)
+
+// handlerRefresh fetches the given feeds right away, whatever their schedule,
+// and reports how each went.
+func handlerRefresh(s *state, cmd command) error {
+	flags := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	all := flags.Bool("all", false, "refresh every feed")
+	following := flags.Bool("following", false, "refresh the feeds the current user follows")
+	err := flags.Parse(cmd.Args)
+	if err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	urls := flags.Args()
+	if *all && *following || (*all || *following) && len(urls) > 0 {
+		return errors.New("use either urls, --all or --following")
+	}
+
+	var feeds []database.Feed
+	switch {
+	case *all:
+		feeds, err = s.db.GetAllFeeds(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to get the feeds: %w", err)
+		}
+	case *following:
+		user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUserName)
+		if err != nil {
+			return fmt.Errorf("failed to get the current user: %w", err)
+		}
+		feeds, err = s.db.GetFollowedFeeds(context.Background(), user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get the followed feeds: %w", err)
+		}
+	case len(urls) > 0:
+		for _, url := range urls {
+			feed, err := getFeedByURL(s, url)
+			if err != nil {
+				return fmt.Errorf("failed to get the feed %s: %w", url, err)
+			}
+			feeds = append(feeds, feed)
+		}
+	default:
+		return errors.New("usage: refresh [<url>...|--all|--following]")
+	}
+
+	failed := 0
+	for _, outcome := range fetchFeeds(s, feeds) {
+		if outcome.Err != nil {
+			failed++
+			fmt.Printf("%s: %v\n", outcome.Feed.Url, outcome.Err)
+			continue
+		}
+		fmt.Printf("%s: %d new posts, %d updated\n", outcome.Feed.Url, outcome.Fetch.PostsInserted, outcome.Fetch.PostsUpdated)
+	}
+	pruneFeedFetches(s)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d feeds failed to refresh", failed, len(feeds))
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/LouisRemes-95/gator/internal/config"
+	"github.com/LouisRemes-95/gator/internal/database"
+)
+
+// pruneInterval is how often agg prunes posts when prune_on_agg is set.
+const pruneInterval = time.Hour
+
+// postRetention is how many posts a feed keeps, zero meaning no limit.
+type postRetention struct {
+	Days     int
+	MaxPosts int
+}
+
+// feedRetention returns the retention of a feed, its own settings
+// overriding the configured ones.
+func feedRetention(cfg *config.Config, feed database.Feed) postRetention {
+	if feed.KeepForever {
+		return postRetention{}
+	}
+
+	retention := postRetention{
+		Days:     cfg.RetentionDays,
+		MaxPosts: cfg.RetentionMaxPosts,
+	}
+	if feed.RetentionDays.Valid {
+		retention.Days = int(feed.RetentionDays.Int32)
+	}
+	if feed.RetentionMaxPosts.Valid {
+		retention.MaxPosts = int(feed.RetentionMaxPosts.Int32)
+	}
+	return retention
+}
+
+func (r postRetention) String() string {
+	if r.Days <= 0 && r.MaxPosts <= 0 {
+		return "forever"
+	}
+	if r.MaxPosts <= 0 {
+		return fmt.Sprintf("%d days", r.Days)
+	}
+	if r.Days <= 0 {
+		return fmt.Sprintf("newest %d posts", r.MaxPosts)
+	}
+	return fmt.Sprintf("%d days, newest %d posts", r.Days, r.MaxPosts)
+}
+
+// retainItems drops the items prune would remove right away, so that they
+// are not stored again on every fetch.
+func retainItems(items []feedItem, retention postRetention) []feedItem {
+	if retention.Days > 0 {
+		cutoff := time.Now().AddDate(0, 0, -retention.Days)
+		kept := items[:0]
+		for _, item := range items {
+			if !item.publishedAt.Before(cutoff) {
+				kept = append(kept, item)
+			}
+		}
+		items = kept
+	}
+
+	if retention.MaxPosts > 0 && len(items) > retention.MaxPosts {
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].publishedAt.After(items[j].publishedAt)
+		})
+		items = items[:retention.MaxPosts]
+	}
+	return items
+}
+
+// prunePosts applies the retention of every feed, then deletes the posts no
+// feed keeps any more.
+func prunePosts(s *state) (links, posts int64, err error) {
+	tx, err := s.sqlDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	links, err = qtx.PruneFeedPosts(context.Background(), database.PruneFeedPostsParams{
+		DefaultDays:     int32(s.cfg.RetentionDays),
+		DefaultMaxPosts: int32(s.cfg.RetentionMaxPosts),
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to prune the feed posts: %w", err)
+	}
+
+	posts, err = qtx.DeleteOrphanedPosts(context.Background())
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to delete the orphaned posts: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to commit: %w", err)
+	}
+	return links, posts, nil
+}
+
+func handlerPrune(s *state, _ command) error {
+	links, posts, err := prunePosts(s)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d posts removed from their feeds, %d posts deleted\n", links, posts)
+	return nil
+}
+
+func handlerFeedRetention(s *state, cmd command) error {
+	flags := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	days := flags.Int("days", 0, "keep posts this many days, 0 for no age limit")
+	maxPosts := flags.Int("max-posts", 0, "keep this many newest posts, 0 for no count limit")
+	forever := flags.Bool("forever", false, "never prune this feed")
+	reset := flags.Bool("default", false, "go back to the configured retention")
+	err := flags.Parse(cmd.Args)
+	if err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+	args := flags.Args()
+
+	if len(args) == 0 {
+		return errors.New("usage: feed retention [--days n] [--max-posts n] [--forever] [--default] <url>")
+	}
+
+	feed, err := getFeedByURL(s, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get feed by url: %w", err)
+	}
+
+	params := database.SetFeedRetentionParams{
+		ID:                feed.ID,
+		RetentionDays:     feed.RetentionDays,
+		RetentionMaxPosts: feed.RetentionMaxPosts,
+		KeepForever:       feed.KeepForever,
+	}
+	changed := false
+	flags.Visit(func(f *flag.Flag) {
+		changed = true
+		switch f.Name {
+		case "days":
+			params.RetentionDays = sql.NullInt32{Int32: int32(*days), Valid: true}
+		case "max-posts":
+			params.RetentionMaxPosts = sql.NullInt32{Int32: int32(*maxPosts), Valid: true}
+		case "forever":
+			params.KeepForever = *forever
+		}
+	})
+	if *reset {
+		params = database.SetFeedRetentionParams{
+			ID: feed.ID,
+		}
+	}
+
+	if changed {
+		err = s.db.SetFeedRetention(context.Background(), params)
+		if err != nil {
+			return fmt.Errorf("failed to set the feed retention: %w", err)
+		}
+		feed.RetentionDays = params.RetentionDays
+		feed.RetentionMaxPosts = params.RetentionMaxPosts
+		feed.KeepForever = params.KeepForever
+	}
+
+	fmt.Println("Retention: ", feedRetention(s.cfg, feed))
+	return nil
+}
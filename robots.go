@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	robotsTTL = 24 * time.Hour
+	// maxRobotsBytes is the part of a robots.txt that is parsed, RFC 9309
+	// asks crawlers to read at least 500 KiB.
+	maxRobotsBytes = 500 << 10
+)
+
+// errRobotsDisallowed is returned for URLs the host's robots.txt keeps our
+// User-Agent away from.
+var errRobotsDisallowed = errors.New("disallowed by robots.txt")
+
+// robotsChecker downloads and caches the robots.txt of every host fetched.
+type robotsChecker struct {
+	mu    sync.Mutex
+	cache map[string]robotsEntry
+}
+
+type robotsEntry struct {
+	rules   *robotsRules
+	expires time.Time
+}
+
+func newRobotsChecker() *robotsChecker {
+	return &robotsChecker{
+		cache: make(map[string]robotsEntry),
+	}
+}
+
+// robotsAllowed reports whether robots.txt lets us fetch rawURL. It always
+// does when the check is turned off.
+func (f *feedFetcher) robotsAllowed(ctx context.Context, rawURL string) (bool, error) {
+	if f.robots == nil {
+		return true, nil
+	}
+
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse url %q: %w", rawURL, err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return true, nil
+	}
+	origin := target.Scheme + "://" + strings.ToLower(target.Host)
+
+	f.robots.mu.Lock()
+	entry, ok := f.robots.cache[origin]
+	f.robots.mu.Unlock()
+
+	if !ok || time.Now().After(entry.expires) {
+		rules, err := f.fetchRobots(ctx, origin)
+		if err != nil {
+			return false, err
+		}
+		entry = robotsEntry{
+			rules:   rules,
+			expires: time.Now().Add(robotsTTL),
+		}
+
+		f.robots.mu.Lock()
+		f.robots.cache[origin] = entry
+		f.robots.mu.Unlock()
+	}
+
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	return entry.rules.allowed(path), nil
+}
+
+// fetchRobots downloads the robots.txt of origin. A missing file allows
+// everything, an unreachable one is an error so the fetch is retried later.
+func (f *feedFetcher) fetchRobots(ctx context.Context, origin string) (*robotsRules, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+	request.Header.Set("User-Agent", f.userAgent)
+
+	response, err := f.do(f.client, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch robots.txt: %w", err)
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		return parseRobots(io.LimitReader(response.Body, maxRobotsBytes), f.userAgent), nil
+	case response.StatusCode >= 400 && response.StatusCode < 500 && response.StatusCode != http.StatusTooManyRequests:
+		return &robotsRules{}, nil
+	default:
+		return nil, fmt.Errorf("failed to fetch robots.txt: %w", newStatusError(response))
+	}
+}
+
+// robotsRules are the allow and disallow lines applying to our User-Agent.
+type robotsRules struct {
+	rules []robotsRule
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// allowed applies the longest matching rule to path, allow winning ties.
+func (r *robotsRules) allowed(path string) bool {
+	allowed := true
+	longest := -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > longest || (rule.length == longest && rule.allow) {
+			allowed = rule.allow
+			longest = rule.length
+		}
+	}
+	return allowed
+}
+
+// robotsGroup is a run of user-agent lines and the rules following them.
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+// parseRobots keeps the groups naming the product token of userAgent, or the
+// "*" groups when none does.
+func parseRobots(body io.Reader, userAgent string) *robotsRules {
+	product := strings.ToLower(userAgent)
+	if i := strings.IndexAny(product, "/ "); i >= 0 {
+		product = product[:i]
+	}
+
+	var groups []*robotsGroup
+	var current *robotsGroup
+	inRules := false
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || inRules {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{
+				allow:   key == "allow",
+				length:  len(value),
+				pattern: robotsPattern(value),
+			})
+		}
+	}
+
+	// A group naming our product applies even when it has no rules, such as
+	// an empty Disallow allowing everything, and hides the "*" groups.
+	var matched, wildcard []robotsRule
+	found := false
+	for _, group := range groups {
+		if slices.Contains(group.agents, product) {
+			found = true
+			matched = append(matched, group.rules...)
+		} else if slices.Contains(group.agents, "*") {
+			wildcard = append(wildcard, group.rules...)
+		}
+	}
+	if found {
+		return &robotsRules{rules: matched}
+	}
+	return &robotsRules{rules: wildcard}
+}
+
+// robotsPattern compiles a rule path, where "*" matches any run of
+// characters and a trailing "$" anchors the end.
+func robotsPattern(path string) *regexp.Regexp {
+	anchored := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+
+	parts := strings.Split(path, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name      string
+		robots    string
+		userAgent string
+		path      string
+		want      bool
+	}{
+		{"empty file", "", "gator", "/feed", true},
+		{"disallow all", "User-agent: *\nDisallow: /\n", "gator", "/feed", false},
+		{"empty disallow", "User-agent: *\nDisallow:\n", "gator", "/feed", true},
+		{"prefix", "User-agent: *\nDisallow: /private\n", "gator", "/private/feed", false},
+		{"other prefix", "User-agent: *\nDisallow: /private\n", "gator", "/public/feed", true},
+		{"longest rule wins", "User-agent: *\nDisallow: /blog\nAllow: /blog/feed\n", "gator", "/blog/feed", true},
+		{"longest disallow wins", "User-agent: *\nAllow: /blog\nDisallow: /blog/drafts\n", "gator", "/blog/drafts/feed", false},
+		{"allow wins ties", "User-agent: *\nDisallow: /feed\nAllow: /feed\n", "gator", "/feed", true},
+		{"wildcard", "User-agent: *\nDisallow: /*.xml\n", "gator", "/blog/rss.xml", false},
+		{"anchored", "User-agent: *\nDisallow: /*.xml$\n", "gator", "/rss.xml?page=2", true},
+		{"anchored match", "User-agent: *\nDisallow: /*.xml$\n", "gator", "/rss.xml", false},
+		{"comments", "# robots\nUser-agent: * # all\nDisallow: /feed # no feeds\n", "gator", "/feed", false},
+		{"field case", "USER-AGENT: *\nDISALLOW: /feed\n", "gator", "/feed", false},
+		{"rules before any agent", "Disallow: /\nUser-agent: *\nAllow: /\n", "gator", "/feed", true},
+		{"own group wins", "User-agent: *\nDisallow: /\n\nUser-agent: gator\nAllow: /\n", "gator", "/feed", true},
+		{"own group replaces star", "User-agent: *\nDisallow: /feed\n\nUser-agent: gator\nDisallow: /other\n", "gator", "/feed", true},
+		{"own group without rules", "User-agent: *\nDisallow: /\n\nUser-agent: gator\nDisallow:\n", "gator", "/feed", true},
+		{"own group listed second", "User-agent: *\nUser-agent: gator\nDisallow: /feed\n", "gator", "/feed", false},
+		{"product token", "User-agent: gator\nDisallow: /\n", "gator/1.0 (+https://example.com)", "/feed", false},
+		{"agent case", "User-agent: Gator\nDisallow: /\n", "gator", "/feed", false},
+		{"other agent ignored", "User-agent: otherbot\nDisallow: /\n", "gator", "/feed", true},
+		{"own groups merged", "User-agent: gator\nDisallow: /a\n\nUser-agent: gator\nDisallow: /b\n", "gator", "/b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(tt.robots), tt.userAgent)
+			got := rules.allowed(tt.path)
+			if got != tt.want {
+				t.Errorf("allowed(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
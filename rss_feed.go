@@ -1,53 +1,116 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"html"
 	"io"
+	"mime"
 	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/html/charset"
 )
 
 type RSSFeed struct {
+	XMLName xml.Name `xml:"rss"`
 	Channel struct {
 		Title       string    `xml:"title"`
 		Link        string    `xml:"link"`
 		Description string    `xml:"description"`
+		Language    string    `xml:"language"`
+		Generator   string    `xml:"generator"`
+		Image       RSSImage  `xml:"image"`
 		Item        []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
+type RSSImage struct {
+	URL string `xml:"url"`
+}
+
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	GUID        string   `xml:"guid"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string `xml:"category"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	request, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create http request: %w", err)
+// AuthorName returns the item's author, falling back to dc:creator.
+func (item RSSItem) AuthorName() string {
+	if item.Author != "" {
+		return item.Author
 	}
+	return item.Creator
+}
 
-	request.Header.Set("User-Agent", "gator")
+// fetchResult is what fetchFeed learned about a feed.
+type fetchResult struct {
+	Feed *RSSFeed
+	// PermanentURL is the final URL when the feed was only reached through
+	// 301 or 308 redirects, and empty otherwise.
+	PermanentURL string
+	// StatusCode is zero when no response was received, Bytes counts the body
+	// as transferred, before decompression.
+	StatusCode int
+	Bytes      int64
+}
 
-	client := &http.Client{}
-	response, err := client.Do(request)
+// fetchFeed fetches and decodes an RSS, Atom or JSON feed. The result is never nil so that the
+// status and size are known for failed fetches too.
+func (f *feedFetcher) fetchFeed(ctx context.Context, feedURL string, opts fetchOptions) (*fetchResult, error) {
+	result := &fetchResult{}
+	request, err := f.newGetRequest(ctx, feedURL, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to do the request: %w", err)
+		return result, err
 	}
+	// Waiting for the host is politeness, not latency: the clock starts once
+	// the request may go out and stops after the body is decoded.
+	err = f.wait(request)
+	if err != nil {
+		return result, err
+	}
+	timer := prometheus.NewTimer(feedFetchDuration)
+	defer timer.ObserveDuration()
 
-	body, err := io.ReadAll(response.Body)
+	response, err := f.send(f.client, request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return result, err
+	}
+	defer response.Body.Close()
+	result.StatusCode = response.StatusCode
+
+	counter := &countingReader{
+		ReadCloser: response.Body,
+	}
+	response.Body = counter
+	defer func() {
+		result.Bytes = counter.n
+		feedFetchBytes.Add(float64(counter.n))
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return result, newStatusError(response)
 	}
 
-	feed := &RSSFeed{}
-	err = xml.Unmarshal(body, feed)
+	body, err := f.body(response)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal the body: %w", err)
+		return result, err
+	}
+
+	feed, err := decodeFeed(body, response.Header.Get("Content-Type"))
+	if err != nil {
+		return result, fmt.Errorf("failed to decode the body: %w", err)
 	}
 
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
@@ -55,7 +118,147 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	for i := range feed.Channel.Item {
 		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
 		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+		feed.Channel.Item[i].GUID = strings.TrimSpace(feed.Channel.Item[i].GUID)
+		feed.Channel.Item[i].Author = html.UnescapeString(strings.TrimSpace(feed.Channel.Item[i].Author))
+		feed.Channel.Item[i].Creator = html.UnescapeString(strings.TrimSpace(feed.Channel.Item[i].Creator))
+	}
+
+	result.Feed = feed
+	if permanentlyRedirected(response) {
+		result.PermanentURL = response.Request.URL.String()
+	}
+	return result, nil
+}
+
+// decodeFeed decodes an RSS, Atom or JSON Feed document, telling them apart
+// by the first character and the root element, as servers label all three
+// loosely.
+func decodeFeed(body io.Reader, contentType string) (*RSSFeed, error) {
+	reader := bufio.NewReader(body)
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			return nil, err
+		}
+		if r == '\uFEFF' || unicode.IsSpace(r) {
+			continue
+		}
+		err = reader.UnreadRune()
+		if err != nil {
+			return nil, err
+		}
+		if r == '{' {
+			return decodeJSONFeed(reader)
+		}
+		break
+	}
+
+	decoder, err := newFeedDecoder(reader, contentType)
+	if err != nil {
+		return nil, err
 	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
 
-	return feed, nil
+		switch start.Name.Local {
+		case "rss":
+			feed := &RSSFeed{}
+			err = decoder.DecodeElement(feed, &start)
+			if err != nil {
+				return nil, err
+			}
+			return feed, nil
+		case "feed":
+			feed := &AtomFeed{}
+			err = decoder.DecodeElement(feed, &start)
+			if err != nil {
+				return nil, err
+			}
+			return feed.RSS(), nil
+		default:
+			return nil, fmt.Errorf("unsupported feed format <%s>", start.Name.Local)
+		}
+	}
+}
+
+func decodeJSONFeed(body io.Reader) (*RSSFeed, error) {
+	feed := &JSONFeed{}
+	err := json.NewDecoder(body).Decode(feed)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(feed.Version, jsonFeedVersionPrefix) {
+		return nil, fmt.Errorf("unsupported JSON feed version %q", feed.Version)
+	}
+	return feed.RSS(), nil
+}
+
+// newFeedDecoder returns a lenient streaming decoder for a feed body that
+// accepts HTML entities such as &nbsp;. A non UTF-8 charset in the
+// Content-Type header wins over the XML prolog; a UTF-8 one does not, as many
+// servers send it by default whatever the document is encoded in.
+func newFeedDecoder(body io.Reader, contentType string) (*xml.Decoder, error) {
+	var headerCharset string
+	_, params, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		headerCharset = strings.ToLower(params["charset"])
+	}
+	if headerCharset == "utf-8" || headerCharset == "utf8" {
+		headerCharset = ""
+	}
+
+	if headerCharset != "" {
+		utf8Body, err := charset.NewReaderLabel(headerCharset, body)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported charset %q: %w", headerCharset, err)
+		}
+		body = utf8Body
+	}
+
+	decoder := xml.NewDecoder(body)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if headerCharset != "" {
+		// Already converted, ignore whatever the prolog declares.
+		decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+			return input, nil
+		}
+	} else {
+		decoder.CharsetReader = charset.NewReaderLabel
+	}
+	return decoder, nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// permanentlyRedirected reports whether the response was reached through at
+// least one redirect, all of them 301 or 308.
+func permanentlyRedirected(response *http.Response) bool {
+	redirect := response.Request.Response
+	if redirect == nil {
+		return false
+	}
+	for ; redirect != nil; redirect = redirect.Request.Response {
+		if redirect.StatusCode != http.StatusMovedPermanently && redirect.StatusCode != http.StatusPermanentRedirect {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeFeed(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		title    string
+		link     string
+		pubDate  string
+		author   string
+		content  string
+		category string
+	}{
+		{
+			name: "rss",
+			body: `<?xml version="1.0"?><rss version="2.0"><channel><title>Blog</title>` +
+				`<item><title>Post</title><link>https://example.com/post</link>` +
+				`<pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate><author>Ann</author>` +
+				`<category>go</category></item></channel></rss>`,
+			title:    "Blog",
+			link:     "https://example.com/post",
+			pubDate:  "Mon, 02 Jan 2006 15:04:05 +0000",
+			author:   "Ann",
+			category: "go",
+		},
+		{
+			name: "atom",
+			body: "\ufeff<?xml version=\"1.0\"?><feed xmlns=\"http://www.w3.org/2005/Atom\"><title>Blog</title>" +
+				`<entry><id>tag:example.com,2006:post</id><title type="html">Post</title>` +
+				`<link rel="edit" href="https://example.com/edit"/><link href="https://example.com/post"/>` +
+				`<updated>2006-01-02T15:04:05Z</updated><author><name>Ann</name></author>` +
+				`<content type="html">&lt;p&gt;Hi&lt;/p&gt;</content><category term="go"/></entry></feed>`,
+			title:    "Blog",
+			link:     "https://example.com/post",
+			pubDate:  "Mon, 02 Jan 2006 15:04:05 +0000",
+			author:   "Ann",
+			content:  "<p>Hi</p>",
+			category: "go",
+		},
+		{
+			name: "atom xhtml content",
+			body: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Blog</title><entry>` +
+				`<published>2006-01-02T16:04:05+01:00</published>` +
+				`<content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Hi</p></div></content></entry></feed>`,
+			title:   "Blog",
+			pubDate: "Mon, 02 Jan 2006 16:04:05 +0100",
+			content: `<div xmlns="http://www.w3.org/1999/xhtml"><p>Hi</p></div>`,
+		},
+		{
+			name: "json feed",
+			body: `  {"version": "https://jsonfeed.org/version/1.1", "title": "Blog", "items": [` +
+				`{"id": "1", "url": "https://example.com/post", "title": "Post", "content_html": "<p>Hi</p>",` +
+				`"date_published": "2006-01-02T15:04:05Z", "authors": [{"name": "Ann"}], "tags": ["go"]}]}`,
+			title:    "Blog",
+			link:     "https://example.com/post",
+			pubDate:  "Mon, 02 Jan 2006 15:04:05 +0000",
+			author:   "Ann",
+			content:  "<p>Hi</p>",
+			category: "go",
+		},
+		{
+			name: "json feed 1.0 author",
+			body: `{"version": "https://jsonfeed.org/version/1", "title": "Blog", "items": [` +
+				`{"id": "1", "content_text": "Hi", "date_published": "2006-01-02T15:04:05Z", "author": {"name": "Ann"}}]}`,
+			title:   "Blog",
+			pubDate: "Mon, 02 Jan 2006 15:04:05 +0000",
+			author:  "Ann",
+			content: "Hi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := decodeFeed(strings.NewReader(tt.body), "")
+			if err != nil {
+				t.Fatalf("decodeFeed: %v", err)
+			}
+			if feed.Channel.Title != tt.title {
+				t.Errorf("title = %q, want %q", feed.Channel.Title, tt.title)
+			}
+			if len(feed.Channel.Item) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Channel.Item))
+			}
+			item := feed.Channel.Item[0]
+			if item.Link != tt.link {
+				t.Errorf("link = %q, want %q", item.Link, tt.link)
+			}
+			if item.PubDate != tt.pubDate {
+				t.Errorf("pubDate = %q, want %q", item.PubDate, tt.pubDate)
+			}
+			if item.AuthorName() != tt.author {
+				t.Errorf("author = %q, want %q", item.AuthorName(), tt.author)
+			}
+			if item.Content != tt.content {
+				t.Errorf("content = %q, want %q", item.Content, tt.content)
+			}
+			var category string
+			if len(item.Categories) > 0 {
+				category = item.Categories[0]
+			}
+			if category != tt.category {
+				t.Errorf("category = %q, want %q", category, tt.category)
+			}
+		})
+	}
+}
+
+func TestDecodeFeedRejectsOtherDocuments(t *testing.T) {
+	for _, body := range []string{
+		"<html><body>not a feed</body></html>",
+		`{"title": "not a feed"}`,
+	} {
+		_, err := decodeFeed(strings.NewReader(body), "")
+		if err == nil {
+			t.Errorf("decodeFeed(%q) succeeded, want an error", body)
+		}
+	}
+}
+
+func TestLooksLikeFeed(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{"rss", `<?xml version="1.0"?><rss version="2.0"><channel>`, true},
+		{"atom", `<feed xmlns="http://www.w3.org/2005/Atom"><title>`, true},
+		{"json feed", `{"version": "https://jsonfeed.org/version/1.1", "items": [`, true},
+		{"json feed version last", `{"title": "Blog", "version": "https://jsonfeed.org/version/1"}`, true},
+		{"html", `<!DOCTYPE html><html><head>`, false},
+		{"other json", `{"title": "Blog"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := looksLikeFeed([]byte(tt.body)); got != tt.want {
+				t.Errorf("looksLikeFeed(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
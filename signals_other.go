@@ -0,0 +1,30 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+)
+
+// aggSignals are the signals agg reacts to between rounds. Only interrupts
+// exist outside unix, reload and refresh never fire.
+type aggSignals struct {
+	shutdown chan os.Signal
+	reload   chan os.Signal
+	refresh  chan os.Signal
+}
+
+func notifySignals() aggSignals {
+	signals := aggSignals{
+		shutdown: make(chan os.Signal, 1),
+		reload:   make(chan os.Signal),
+		refresh:  make(chan os.Signal),
+	}
+	signal.Notify(signals.shutdown, os.Interrupt)
+	return signals
+}
+
+func (a aggSignals) stop() {
+	signal.Stop(a.shutdown)
+}
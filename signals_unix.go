@@ -0,0 +1,34 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// aggSignals are the signals agg reacts to between rounds.
+type aggSignals struct {
+	shutdown chan os.Signal
+	reload   chan os.Signal
+	refresh  chan os.Signal
+}
+
+func notifySignals() aggSignals {
+	signals := aggSignals{
+		shutdown: make(chan os.Signal, 1),
+		reload:   make(chan os.Signal, 1),
+		refresh:  make(chan os.Signal, 1),
+	}
+	signal.Notify(signals.shutdown, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(signals.reload, syscall.SIGHUP)
+	signal.Notify(signals.refresh, syscall.SIGUSR1)
+	return signals
+}
+
+func (a aggSignals) stop() {
+	signal.Stop(a.shutdown)
+	signal.Stop(a.reload)
+	signal.Stop(a.refresh)
+}
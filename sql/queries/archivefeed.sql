@@ -0,0 +1,6 @@
+-- name: ArchiveFeed :exec
+
+UPDATE feeds
+SET archived_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: ClearFeedRedirect :exec
+
+UPDATE feeds
+SET redirect_url = NULL,
+    redirect_count = 0
+WHERE id = $1 AND redirect_url IS NOT NULL;
\ No newline at end of file
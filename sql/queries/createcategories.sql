@@ -0,0 +1,9 @@
+-- name: CreateCategories :exec
+
+INSERT INTO categories (id, name)
+SELECT items.id, items.name
+FROM unnest(
+    sqlc.arg('ids')::uuid[],
+    sqlc.arg('names')::text[]
+) AS items(id, name)
+ON CONFLICT (name) DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- name: CreateFeedFetch :exec
+INSERT INTO feed_fetches (id, feed_id, started_at, finished_at, duration_ms, status_code, bytes, items_seen, posts_inserted, posts_updated, error)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11
+);
\ No newline at end of file
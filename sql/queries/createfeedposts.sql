@@ -0,0 +1,10 @@
+-- name: CreateFeedPosts :exec
+
+INSERT INTO feed_posts (feed_id, post_id, guid)
+SELECT sqlc.arg('feed_id')::uuid, items.post_id, NULLIF(items.guid, '')
+FROM unnest(
+    sqlc.arg('post_ids')::uuid[],
+    sqlc.arg('guids')::text[]
+) AS items(post_id, guid)
+ON CONFLICT (feed_id, post_id)
+DO UPDATE SET guid = EXCLUDED.guid;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- name: CreatePostCategories :exec
+
+INSERT INTO post_categories (post_id, category_id)
+SELECT items.post_id, categories.id
+FROM unnest(
+    sqlc.arg('post_ids')::uuid[],
+    sqlc.arg('names')::text[]
+) AS items(post_id, name)
+INNER JOIN categories ON categories.name = items.name
+ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,32 @@
+-- name: CreatePosts :many
+
+-- A post created meanwhile under the same canonical URL is returned instead
+-- of failing the batch.
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, author, content, content_hash, canonical_url, resolved_url)
+SELECT items.id,
+    NOW(),
+    NOW(),
+    items.title,
+    items.url,
+    NULLIF(items.description, ''),
+    items.published_at,
+    NULLIF(items.author, ''),
+    NULLIF(items.content, ''),
+    NULLIF(items.content_hash, ''),
+    items.canonical_url,
+    NULLIF(items.resolved_url, '')
+FROM unnest(
+    sqlc.arg('ids')::uuid[],
+    sqlc.arg('titles')::text[],
+    sqlc.arg('urls')::text[],
+    sqlc.arg('descriptions')::text[],
+    sqlc.arg('published_ats')::timestamp[],
+    sqlc.arg('authors')::text[],
+    sqlc.arg('contents')::text[],
+    sqlc.arg('content_hashes')::text[],
+    sqlc.arg('canonical_urls')::text[],
+    sqlc.arg('resolved_urls')::text[]
+) AS items(id, title, url, description, published_at, author, content, content_hash, canonical_url, resolved_url)
+ON CONFLICT (canonical_url)
+DO UPDATE SET canonical_url = EXCLUDED.canonical_url
+RETURNING id, canonical_url;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- name: DeleteFeed :exec
+
+DELETE FROM feeds WHERE id = $1;
\ No newline at end of file
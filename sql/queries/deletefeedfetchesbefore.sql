@@ -0,0 +1,4 @@
+-- name: DeleteFeedFetchesBefore :execrows
+
+DELETE FROM feed_fetches
+WHERE started_at < $1;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- name: DeleteOrphanedPosts :execrows
+
+-- Posts no feed links to any more.
+DELETE FROM posts
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM feed_posts
+    WHERE feed_posts.post_id = posts.id
+);
\ No newline at end of file
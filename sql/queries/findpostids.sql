@@ -0,0 +1,24 @@
+-- name: FindPostIDs :many
+
+-- Matches each item, numbered from 1, to a stored post by GUID within the
+-- feed, then by canonical URL, then by content hash.
+SELECT matches.idx::integer AS idx, matches.post_id::uuid AS post_id
+FROM (
+    SELECT items.idx,
+        COALESCE(
+            (SELECT feed_posts.post_id FROM feed_posts
+             WHERE feed_posts.feed_id = sqlc.arg('feed_id') AND feed_posts.guid = items.guid),
+            (SELECT posts.id FROM posts
+             WHERE posts.canonical_url = items.canonical_url),
+            (SELECT posts.id FROM posts
+             WHERE posts.content_hash = items.content_hash
+             ORDER BY posts.created_at ASC
+             LIMIT 1)
+        ) AS post_id
+    FROM unnest(
+        sqlc.arg('guids')::text[],
+        sqlc.arg('canonical_urls')::text[],
+        sqlc.arg('content_hashes')::text[]
+    ) WITH ORDINALITY AS items(guid, canonical_url, content_hash, idx)
+) AS matches
+WHERE matches.post_id IS NOT NULL;
\ No newline at end of file
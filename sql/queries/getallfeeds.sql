@@ -0,0 +1,4 @@
+-- name: GetAllFeeds :many
+
+SELECT * FROM feeds
+ORDER BY name ASC;
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- name: GetDuplicateFeeds :many
+
+-- Pairs of feeds announcing the same site and title.
+SELECT
+    a.name AS feed_name,
+    a.url AS feed_url,
+    b.name AS duplicate_name,
+    b.url AS duplicate_url
+FROM feeds AS a
+INNER JOIN feeds AS b ON a.id < b.id
+    AND a.site_url = b.site_url
+    AND lower(a.channel_title) = lower(b.channel_title)
+ORDER BY a.url ASC, b.url ASC;
\ No newline at end of file
@@ -0,0 +1,32 @@
+-- name: GetFailingFeeds :many
+
+-- consecutive_failures counts the fetches since the last successful one.
+SELECT
+    feeds.id,
+    feeds.name,
+    feeds.url,
+    feeds.fetch_status,
+    feeds.last_fetched_at,
+    last_fetch.error AS last_error,
+    (
+        SELECT COUNT(*)
+        FROM feed_fetches
+        WHERE feed_fetches.feed_id = feeds.id
+            AND feed_fetches.error IS NOT NULL
+            AND feed_fetches.started_at > COALESCE((
+                SELECT MAX(succeeded.started_at)
+                FROM feed_fetches AS succeeded
+                WHERE succeeded.feed_id = feeds.id
+                    AND succeeded.error IS NULL
+            ), '-infinity'::timestamp)
+    ) AS consecutive_failures
+FROM feeds
+LEFT JOIN LATERAL (
+    SELECT feed_fetches.error
+    FROM feed_fetches
+    WHERE feed_fetches.feed_id = feeds.id
+    ORDER BY feed_fetches.started_at DESC
+    LIMIT 1
+) AS last_fetch ON TRUE
+WHERE feeds.fetch_status IN ('error', 'robots_disallowed')
+ORDER BY consecutive_failures DESC, feeds.url ASC;
\ No newline at end of file
@@ -1,3 +1,3 @@
 -- name: GetFeedByUrl :one
 
-SELECT * FROM feeds WHERE url = $1;
\ No newline at end of file
+SELECT * FROM feeds WHERE canonical_url = $1;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: GetFeedFetches :many
+
+SELECT * FROM feed_fetches
+WHERE feed_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
\ No newline at end of file
@@ -3,6 +3,12 @@
 SELECT 
     f.name  AS feed_name,
     f.url   AS feed_url,
-    u.name  AS user_name
+    u.name  AS user_name,
+    f.channel_title,
+    f.site_url,
+    f.description,
+    f.language,
+    f.auth_type,
+    f.fetch_status
 FROM feeds AS f
 JOIN users AS u ON f.user_id = u.id;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: GetFollowedFeeds :many
+
+SELECT feeds.* FROM feeds
+INNER JOIN feed_follow ON feed_follow.feed_id = feeds.id
+WHERE feed_follow.user_id = $1
+ORDER BY feeds.name ASC;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: GetNeverFetchedFeeds :many
+
+SELECT id, name, url, created_at, last_attempted_at
+FROM feeds
+WHERE last_fetched_at IS NULL
+ORDER BY created_at ASC;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- name: GetNextFeedsToFetch :many
+
+-- Feeds nobody follows are skipped, feeds whose host asked us to back off
+-- wait for a later round.
+SELECT * FROM feeds
+WHERE feeds.orphaned_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1
+        FROM host_cooldowns
+        WHERE host_cooldowns.host = lower(substring(feeds.url FROM '^[^:/?#]+://(?:[^@/?#]*@)?([^/:?#]+)'))
+            AND host_cooldowns.until > NOW()
+    )
+ORDER BY last_attempted_at ASC NULLS FIRST
+LIMIT $1;
\ No newline at end of file
@@ -1,5 +0,0 @@
--- name: GetNextFeedToFetch :one
-
-SELECT * FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: GetOrphanedFeeds :many
+
+SELECT id, name, url, orphaned_at, archived_at
+FROM feeds
+WHERE orphaned_at < $1
+ORDER BY orphaned_at ASC;
\ No newline at end of file
@@ -1,10 +1,18 @@
 -- name: GetPostsforUser :many
 
-SELECT posts.*
+SELECT DISTINCT posts.*
 FROM users 
 LEFT JOIN feed_follow ON users.id = feed_follow.user_id
-LEFT JOIN feeds ON feed_follow.feed_id = feeds.id
-LEFT JOIN posts ON feeds.id = posts.feed_id
-WHERE users.name = $1
+LEFT JOIN feed_posts ON feed_follow.feed_id = feed_posts.feed_id
+LEFT JOIN posts ON feed_posts.post_id = posts.id
+WHERE users.name = sqlc.arg('name')
+    AND (sqlc.narg('author')::text IS NULL OR posts.author = sqlc.narg('author'))
+    AND (sqlc.narg('category')::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM post_categories
+        INNER JOIN categories ON post_categories.category_id = categories.id
+        WHERE post_categories.post_id = posts.id
+            AND categories.name = sqlc.narg('category')
+    ))
 ORDER BY published_at DESC
-LIMIT $2;
\ No newline at end of file
+LIMIT sqlc.arg('limit');
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- name: GetResolvedUrl :one
+
+SELECT resolved_url FROM posts
+WHERE url = $1 AND resolved_url IS NOT NULL
+LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- name: GetSchedulerLag :one
+
+-- Seconds since the feed waiting longest was last attempted, feeds never
+-- attempted count from their creation.
+SELECT COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(COALESCE(last_attempted_at, created_at))), 0)::float8 AS lag_seconds
+FROM feeds
+WHERE orphaned_at IS NULL;
\ No newline at end of file
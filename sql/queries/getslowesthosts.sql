@@ -0,0 +1,13 @@
+-- name: GetSlowestHosts :many
+
+SELECT
+    lower(substring(feeds.url FROM '^[^:/?#]+://(?:[^@/?#]*@)?([^/:?#]+)'))::text AS host,
+    COUNT(*) AS fetches,
+    AVG(feed_fetches.duration_ms)::bigint AS avg_duration_ms,
+    MAX(feed_fetches.duration_ms)::bigint AS max_duration_ms
+FROM feed_fetches
+INNER JOIN feeds ON feeds.id = feed_fetches.feed_id
+WHERE feed_fetches.started_at >= $1
+GROUP BY host
+ORDER BY avg_duration_ms DESC
+LIMIT $2;
\ No newline at end of file
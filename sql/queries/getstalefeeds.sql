@@ -0,0 +1,11 @@
+-- name: GetStaleFeeds :many
+
+-- Fetched feeds whose newest post is older than the cutoff, or with none.
+SELECT feeds.id, feeds.name, feeds.url, MAX(posts.published_at) AS last_published_at
+FROM feeds
+LEFT JOIN feed_posts ON feed_posts.feed_id = feeds.id
+LEFT JOIN posts ON posts.id = feed_posts.post_id
+WHERE feeds.last_fetched_at IS NOT NULL
+GROUP BY feeds.id
+HAVING MAX(posts.published_at) IS NULL OR MAX(posts.published_at) < sqlc.arg('cutoff')::timestamp
+ORDER BY last_published_at ASC NULLS FIRST;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- name: GetUnfollowedFeeds :many
+
+SELECT id, name, url, created_at
+FROM feeds
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM feed_follow
+    WHERE feed_follow.feed_id = feeds.id
+)
+ORDER BY created_at ASC;
\ No newline at end of file
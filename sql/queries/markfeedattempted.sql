@@ -0,0 +1,5 @@
+-- name: MarkFeedAttempted :exec
+
+UPDATE feeds
+SET last_attempted_at = NOW()
+WHERE id = $1;
\ No newline at end of file
@@ -2,5 +2,8 @@
 
 UPDATE feeds
 SET last_fetched_at = NOW(),
-    updated_at = NOW()
+    updated_at = NOW(),
+    last_posts_inserted = $2,
+    last_posts_updated = $3,
+    last_posts_skipped = $4
 WHERE id = $1;
\ No newline at end of file
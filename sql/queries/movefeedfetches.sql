@@ -0,0 +1,5 @@
+-- name: MoveFeedFetches :exec
+
+UPDATE feed_fetches
+SET feed_id = sqlc.arg('to_feed_id')
+WHERE feed_id = sqlc.arg('from_feed_id');
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- name: MoveFeedPosts :exec
+
+UPDATE feed_posts
+SET feed_id = sqlc.arg('to_feed_id')
+WHERE feed_id = sqlc.arg('from_feed_id')
+    AND post_id NOT IN (
+        SELECT post_id FROM feed_posts WHERE feed_id = sqlc.arg('to_feed_id')
+    )
+    AND (guid IS NULL OR guid NOT IN (
+        SELECT guid FROM feed_posts WHERE feed_id = sqlc.arg('to_feed_id') AND guid IS NOT NULL
+    ));
\ No newline at end of file
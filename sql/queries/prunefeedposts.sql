@@ -0,0 +1,32 @@
+-- name: PruneFeedPosts :execrows
+
+-- Unlinks the posts a feed keeps no more: older than its retention in days,
+-- or beyond its newest retention_max_posts. Feed settings override the
+-- defaults, zero meaning no limit, and keep_forever feeds are left alone.
+DELETE FROM feed_posts
+USING feeds, (
+    SELECT
+        feed_posts.feed_id,
+        feed_posts.post_id,
+        posts.published_at,
+        ROW_NUMBER() OVER (
+            PARTITION BY feed_posts.feed_id
+            ORDER BY posts.published_at DESC
+        ) AS position
+    FROM feed_posts
+    INNER JOIN posts ON posts.id = feed_posts.post_id
+) AS ranked
+WHERE feeds.id = feed_posts.feed_id
+    AND ranked.feed_id = feed_posts.feed_id
+    AND ranked.post_id = feed_posts.post_id
+    AND NOT feeds.keep_forever
+    AND (
+        (
+            COALESCE(feeds.retention_days, sqlc.arg('default_days')::integer) > 0
+            AND ranked.published_at < NOW() - make_interval(days => COALESCE(feeds.retention_days, sqlc.arg('default_days')::integer))
+        )
+        OR (
+            COALESCE(feeds.retention_max_posts, sqlc.arg('default_max_posts')::integer) > 0
+            AND ranked.position > COALESCE(feeds.retention_max_posts, sqlc.arg('default_max_posts')::integer)
+        )
+    );
\ No newline at end of file
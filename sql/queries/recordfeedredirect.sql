@@ -0,0 +1,7 @@
+-- name: RecordFeedRedirect :one
+
+UPDATE feeds
+SET redirect_count = CASE WHEN redirect_url = $2 THEN redirect_count + 1 ELSE 1 END,
+    redirect_url = $2
+WHERE id = $1
+RETURNING redirect_count;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- name: SetFeedAuth :exec
+
+UPDATE feeds
+SET auth_type = $2,
+    auth_secret = $3,
+    updated_at = NOW()
+WHERE id = $1;
\ No newline at end of file
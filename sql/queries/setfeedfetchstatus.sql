@@ -0,0 +1,5 @@
+-- name: SetFeedFetchStatus :exec
+
+UPDATE feeds
+SET fetch_status = $2
+WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: SetFeedHeaders :exec
+
+UPDATE feeds
+SET headers = $2,
+    updated_at = NOW()
+WHERE id = $1;
\ No newline at end of file
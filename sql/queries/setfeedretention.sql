@@ -0,0 +1,8 @@
+-- name: SetFeedRetention :exec
+
+UPDATE feeds
+SET retention_days = $2,
+    retention_max_posts = $3,
+    keep_forever = $4,
+    updated_at = NOW()
+WHERE id = $1;
\ No newline at end of file
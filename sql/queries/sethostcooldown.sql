@@ -0,0 +1,6 @@
+-- name: SetHostCooldown :exec
+
+INSERT INTO host_cooldowns (host, until)
+VALUES ($1, $2)
+ON CONFLICT (host) DO UPDATE
+SET until = GREATEST(host_cooldowns.until, EXCLUDED.until);
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- name: UpdateFeedMetadata :exec
+
+UPDATE feeds
+SET channel_title = $2,
+    site_url = $3,
+    description = $4,
+    language = $5,
+    image_url = $6,
+    generator = $7,
+    updated_at = NOW()
+WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- name: UpdateFeedUrl :exec
+
+UPDATE feeds
+SET url = $2,
+    canonical_url = $3,
+    redirect_url = NULL,
+    redirect_count = 0,
+    updated_at = NOW()
+WHERE id = $1;
\ No newline at end of file
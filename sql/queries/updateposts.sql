@@ -0,0 +1,21 @@
+-- name: UpdatePosts :execrows
+
+UPDATE posts
+SET updated_at = NOW(),
+    title = items.title,
+    description = NULLIF(items.description, ''),
+    published_at = items.published_at,
+    author = NULLIF(items.author, ''),
+    content = NULLIF(items.content, ''),
+    content_hash = NULLIF(items.content_hash, '')
+FROM unnest(
+    sqlc.arg('ids')::uuid[],
+    sqlc.arg('titles')::text[],
+    sqlc.arg('descriptions')::text[],
+    sqlc.arg('published_ats')::timestamp[],
+    sqlc.arg('authors')::text[],
+    sqlc.arg('contents')::text[],
+    sqlc.arg('content_hashes')::text[]
+) AS items(id, title, description, published_at, author, content, content_hash)
+WHERE posts.id = items.id
+    AND (posts.content_hash IS DISTINCT FROM NULLIF(items.content_hash, '') OR posts.published_at < items.published_at);
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN description TEXT,
+ADD COLUMN site_url TEXT;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN site_url,
+DROP COLUMN description;
\ No newline at end of file
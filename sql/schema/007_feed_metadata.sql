@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN channel_title TEXT,
+ADD COLUMN language TEXT,
+ADD COLUMN image_url TEXT,
+ADD COLUMN generator TEXT;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN generator,
+DROP COLUMN image_url,
+DROP COLUMN language,
+DROP COLUMN channel_title;
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN guid TEXT,
+ADD COLUMN author TEXT,
+ADD COLUMN content TEXT;
+
+CREATE TABLE categories (
+    id UUID PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE post_categories (
+    post_id UUID NOT NULL,
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+        ON DELETE CASCADE,
+    category_id UUID NOT NULL,
+    FOREIGN KEY (category_id) REFERENCES categories(id)
+        ON DELETE CASCADE,
+    PRIMARY KEY (post_id, category_id)
+);
+
+-- +goose Down
+DROP TABLE post_categories;
+DROP TABLE categories;
+
+ALTER TABLE posts
+DROP COLUMN content,
+DROP COLUMN author,
+DROP COLUMN guid;
\ No newline at end of file
@@ -0,0 +1,53 @@
+-- +goose Up
+CREATE TABLE feed_posts (
+    feed_id UUID NOT NULL,
+    FOREIGN KEY (feed_id) REFERENCES feeds(id)
+        ON DELETE CASCADE,
+    post_id UUID NOT NULL,
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+        ON DELETE CASCADE,
+    guid TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (feed_id, post_id)
+);
+
+CREATE UNIQUE INDEX feed_posts_feed_id_guid ON feed_posts (feed_id, guid);
+
+INSERT INTO feed_posts (feed_id, post_id, guid, created_at)
+SELECT feed_id, id, guid, created_at FROM posts;
+
+ALTER TABLE posts
+ADD COLUMN content_hash TEXT;
+
+-- Mirrors contentHash in posts.go: posts without a body get no hash.
+UPDATE posts
+SET content_hash = encode(sha256(convert_to(
+    lower(btrim(title, E' \t\r\n')) || E'\n' || btrim(COALESCE(NULLIF(content, ''), description, ''), E' \t\r\n'),
+    'UTF8'
+)), 'hex')
+WHERE btrim(COALESCE(NULLIF(content, ''), description, ''), E' \t\r\n') <> '';
+
+CREATE INDEX posts_content_hash ON posts (content_hash);
+
+ALTER TABLE posts
+DROP COLUMN feed_id,
+DROP COLUMN guid;
+
+-- +goose Down
+ALTER TABLE posts
+ADD COLUMN feed_id UUID REFERENCES feeds(id) ON DELETE CASCADE,
+ADD COLUMN guid TEXT;
+
+UPDATE posts
+SET feed_id = feed_posts.feed_id,
+    guid = feed_posts.guid
+FROM feed_posts
+WHERE feed_posts.post_id = posts.id;
+
+DELETE FROM posts WHERE feed_id IS NULL;
+
+ALTER TABLE posts
+ALTER COLUMN feed_id SET NOT NULL,
+DROP COLUMN content_hash;
+
+DROP TABLE feed_posts;
\ No newline at end of file
@@ -0,0 +1,155 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Mirrors canonical.URL in internal/canonical, only used for the backfill.
+CREATE FUNCTION gator_canonical_url(raw TEXT) RETURNS TEXT AS $$
+DECLARE
+    rest TEXT;
+    host TEXT;
+    path TEXT;
+    query TEXT;
+BEGIN
+    rest := regexp_replace(btrim(raw), '^[A-Za-z][A-Za-z0-9+.-]*://', '');
+    rest := regexp_replace(rest, '#.*$', '');
+
+    host := lower(substring(rest FROM '^[^/?]*'));
+    host := regexp_replace(host, '^www\.', '');
+    host := regexp_replace(host, ':(80|443)$', '');
+
+    path := regexp_replace(substring(rest FROM '^[^/?]*([^?]*)'), '/+$', '');
+    IF path = '' THEN
+        path := '/';
+    END IF;
+
+    query := array_to_string(ARRAY(
+        SELECT param
+        FROM unnest(string_to_array(substring(rest FROM '\?(.*)$'), '&')) WITH ORDINALITY AS t(param, n)
+        WHERE param <> ''
+            AND lower(split_part(param, '=', 1)) NOT LIKE 'utm\_%'
+            AND lower(split_part(param, '=', 1)) NOT IN (
+                'fbclid', 'gclid', 'dclid', 'msclkid', 'yclid', 'igshid',
+                'mc_cid', 'mc_eid', '_hsenc', '_hsmi', 'mkt_tok'
+            )
+        ORDER BY n
+    ), '&');
+
+    IF query = '' THEN
+        RETURN 'https://' || host || path;
+    END IF;
+    RETURN 'https://' || host || path || '?' || query;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+ALTER TABLE feeds
+ADD COLUMN canonical_url TEXT;
+
+UPDATE feeds SET canonical_url = gator_canonical_url(url);
+
+-- Merge duplicate feeds into the oldest one, keeping every follow.
+CREATE TEMPORARY TABLE feed_merges AS
+SELECT id AS duplicate_id, keeper_id
+FROM (
+    SELECT
+        id,
+        first_value(id) OVER (PARTITION BY canonical_url ORDER BY created_at, id) AS keeper_id
+    FROM feeds
+) AS ranked
+WHERE id <> keeper_id;
+
+UPDATE feed_follow
+SET feed_id = moved.keeper_id
+FROM (
+    SELECT DISTINCT ON (feed_merges.keeper_id, feed_follow.user_id)
+        feed_follow.id,
+        feed_merges.keeper_id
+    FROM feed_follow
+    INNER JOIN feed_merges ON feed_follow.feed_id = feed_merges.duplicate_id
+    WHERE NOT EXISTS (
+        SELECT 1 FROM feed_follow AS kept
+        WHERE kept.feed_id = feed_merges.keeper_id AND kept.user_id = feed_follow.user_id
+    )
+    ORDER BY feed_merges.keeper_id, feed_follow.user_id, feed_follow.created_at
+) AS moved
+WHERE feed_follow.id = moved.id;
+
+UPDATE feed_posts
+SET feed_id = moved.keeper_id,
+    guid = NULL
+FROM (
+    SELECT DISTINCT ON (feed_merges.keeper_id, feed_posts.post_id)
+        feed_posts.feed_id,
+        feed_posts.post_id,
+        feed_merges.keeper_id
+    FROM feed_posts
+    INNER JOIN feed_merges ON feed_posts.feed_id = feed_merges.duplicate_id
+    WHERE NOT EXISTS (
+        SELECT 1 FROM feed_posts AS kept
+        WHERE kept.feed_id = feed_merges.keeper_id AND kept.post_id = feed_posts.post_id
+    )
+    ORDER BY feed_merges.keeper_id, feed_posts.post_id, feed_posts.created_at
+) AS moved
+WHERE feed_posts.feed_id = moved.feed_id AND feed_posts.post_id = moved.post_id;
+
+DELETE FROM feeds WHERE id IN (SELECT duplicate_id FROM feed_merges);
+
+ALTER TABLE feeds
+ALTER COLUMN canonical_url SET NOT NULL,
+ADD CONSTRAINT feeds_canonical_url_key UNIQUE (canonical_url);
+
+ALTER TABLE posts
+ADD COLUMN canonical_url TEXT;
+
+UPDATE posts SET canonical_url = gator_canonical_url(url);
+
+-- Merge duplicate posts into the oldest one, keeping every feed link.
+CREATE TEMPORARY TABLE post_merges AS
+SELECT id AS duplicate_id, keeper_id
+FROM (
+    SELECT
+        id,
+        first_value(id) OVER (PARTITION BY canonical_url ORDER BY created_at, id) AS keeper_id
+    FROM posts
+) AS ranked
+WHERE id <> keeper_id;
+
+UPDATE feed_posts
+SET post_id = moved.keeper_id
+FROM (
+    SELECT DISTINCT ON (feed_posts.feed_id, post_merges.keeper_id)
+        feed_posts.feed_id,
+        feed_posts.post_id,
+        post_merges.keeper_id
+    FROM feed_posts
+    INNER JOIN post_merges ON feed_posts.post_id = post_merges.duplicate_id
+    WHERE NOT EXISTS (
+        SELECT 1 FROM feed_posts AS kept
+        WHERE kept.feed_id = feed_posts.feed_id AND kept.post_id = post_merges.keeper_id
+    )
+    ORDER BY feed_posts.feed_id, post_merges.keeper_id, feed_posts.created_at
+) AS moved
+WHERE feed_posts.feed_id = moved.feed_id AND feed_posts.post_id = moved.post_id;
+
+INSERT INTO post_categories (post_id, category_id)
+SELECT post_merges.keeper_id, post_categories.category_id
+FROM post_categories
+INNER JOIN post_merges ON post_categories.post_id = post_merges.duplicate_id
+ON CONFLICT DO NOTHING;
+
+DELETE FROM posts WHERE id IN (SELECT duplicate_id FROM post_merges);
+
+ALTER TABLE posts
+ALTER COLUMN canonical_url SET NOT NULL,
+ADD CONSTRAINT posts_canonical_url_key UNIQUE (canonical_url),
+DROP CONSTRAINT posts_url_key;
+
+DROP TABLE post_merges;
+DROP TABLE feed_merges;
+DROP FUNCTION gator_canonical_url(TEXT);
+
+-- +goose Down
+ALTER TABLE posts
+ADD CONSTRAINT posts_url_key UNIQUE (url),
+DROP COLUMN canonical_url;
+
+ALTER TABLE feeds
+DROP COLUMN canonical_url;
\ No newline at end of file
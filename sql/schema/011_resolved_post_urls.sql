@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN resolved_url TEXT;
+
+CREATE INDEX posts_url ON posts (url);
+
+-- +goose Down
+DROP INDEX posts_url;
+
+ALTER TABLE posts
+DROP COLUMN resolved_url;
\ No newline at end of file
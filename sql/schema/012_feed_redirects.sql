@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN redirect_url TEXT,
+ADD COLUMN redirect_count INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN redirect_count,
+DROP COLUMN redirect_url;
\ No newline at end of file
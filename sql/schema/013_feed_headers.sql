@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN headers JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN headers;
\ No newline at end of file
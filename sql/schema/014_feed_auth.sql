@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN auth_type TEXT,
+ADD COLUMN auth_secret BYTEA;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN auth_secret,
+DROP COLUMN auth_type;
\ No newline at end of file
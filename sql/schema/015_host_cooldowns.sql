@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE host_cooldowns (
+    host TEXT PRIMARY KEY,
+    until TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE host_cooldowns;
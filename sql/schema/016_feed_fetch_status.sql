@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN fetch_status TEXT;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN fetch_status;
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN last_attempted_at TIMESTAMP,
+ADD COLUMN last_posts_inserted INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN last_posts_updated INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN last_posts_skipped INTEGER NOT NULL DEFAULT 0;
+
+UPDATE feeds
+SET last_attempted_at = last_fetched_at;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN last_attempted_at,
+DROP COLUMN last_posts_inserted,
+DROP COLUMN last_posts_updated,
+DROP COLUMN last_posts_skipped;
\ No newline at end of file
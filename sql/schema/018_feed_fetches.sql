@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE feed_fetches (
+    id UUID PRIMARY KEY,
+    feed_id UUID NOT NULL,
+    FOREIGN KEY (feed_id) REFERENCES feeds(id)
+        ON DELETE CASCADE,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    status_code INTEGER,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    items_seen INTEGER NOT NULL DEFAULT 0,
+    posts_inserted INTEGER NOT NULL DEFAULT 0,
+    posts_updated INTEGER NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+CREATE INDEX feed_fetches_feed_id_started_at ON feed_fetches (feed_id, started_at);
+CREATE INDEX feed_fetches_started_at ON feed_fetches (started_at);
+
+-- +goose Down
+DROP TABLE feed_fetches;
\ No newline at end of file
@@ -0,0 +1,55 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN orphaned_at TIMESTAMP,
+ADD COLUMN archived_at TIMESTAMP;
+
+UPDATE feeds
+SET orphaned_at = NOW()
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM feed_follow
+    WHERE feed_follow.feed_id = feeds.id
+);
+
+-- +goose StatementBegin
+-- Keeps feeds.orphaned_at set while a feed has no follower, whichever way the
+-- last follow went away: unfollow, user deletion or feed merge. Following a
+-- feed again also brings it back from the archive.
+CREATE FUNCTION gator_track_orphaned_feeds() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE feeds
+        SET orphaned_at = NULL,
+            archived_at = NULL
+        WHERE id = NEW.feed_id
+            AND orphaned_at IS NOT NULL;
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE feeds
+        SET orphaned_at = NOW()
+        WHERE id = OLD.feed_id
+            AND orphaned_at IS NULL
+            AND NOT EXISTS (
+                SELECT 1
+                FROM feed_follow
+                WHERE feed_follow.feed_id = OLD.feed_id
+            );
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER feed_follow_orphaned_feeds
+AFTER INSERT OR UPDATE OF feed_id OR DELETE ON feed_follow
+FOR EACH ROW EXECUTE FUNCTION gator_track_orphaned_feeds();
+
+-- +goose Down
+DROP TRIGGER feed_follow_orphaned_feeds ON feed_follow;
+DROP FUNCTION gator_track_orphaned_feeds();
+
+ALTER TABLE feeds
+DROP COLUMN orphaned_at,
+DROP COLUMN archived_at;
\ No newline at end of file
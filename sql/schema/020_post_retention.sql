@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN retention_days INTEGER,
+ADD COLUMN retention_max_posts INTEGER,
+ADD COLUMN keep_forever BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX posts_published_at ON posts (published_at);
+
+-- +goose Down
+DROP INDEX posts_published_at;
+
+ALTER TABLE feeds
+DROP COLUMN retention_days,
+DROP COLUMN retention_max_posts,
+DROP COLUMN keep_forever;
\ No newline at end of file